@@ -0,0 +1,143 @@
+package blockhash
+
+import (
+	"errors"
+
+	sgo "github.com/SolmateDev/solana-go"
+	"github.com/solpipe/solpipe-util/ds/ring"
+)
+
+// number of slots a blockhash stays valid after the slot it was produced in
+const DEFAULT_MAX_AGE uint64 = 150
+
+type entry struct {
+	hash sgo.Hash
+	slot uint64
+}
+
+// Cache keeps the blockhashes of the most recent slots in a ring, with a
+// map from blockhash to ring id so lookups are O(1). Blockhashes are
+// evicted as newer slots arrive.
+//
+// Cache is not safe for concurrent use.
+type Cache struct {
+	r        *ring.Ring[entry]
+	index    map[sgo.Hash]uint64
+	maxAge   uint64
+	slot     uint64
+	onExpire []func(hash sgo.Hash, slot uint64)
+}
+
+// create a cache where a blockhash from slot s is valid up to and
+// including slot s+maxAge
+func Create(maxAge uint64) (*Cache, error) {
+	if maxAge == 0 {
+		return nil, errors.New("max age is zero")
+	}
+	r, err := ring.Create[entry](maxAge + 1)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Cache)
+	c.r = r
+	c.index = make(map[sgo.Hash]uint64)
+	c.maxAge = maxAge
+	c.slot = 0
+	c.onExpire = make([]func(sgo.Hash, uint64), 0)
+	return c, nil
+}
+
+// register a callback that is run for every blockhash as it expires
+func (c *Cache) OnExpire(callback func(hash sgo.Hash, slot uint64)) {
+	c.onExpire = append(c.onExpire, callback)
+}
+
+// returns the latest slot seen
+func (c *Cache) Slot() uint64 {
+	return c.slot
+}
+
+// returns the number of valid blockhashes
+func (c *Cache) Length() uint {
+	return c.r.Length()
+}
+
+// record the blockhash produced in slot.
+// slots must not go backwards.
+func (c *Cache) Add(slot uint64, hash sgo.Hash) error {
+	if slot < c.slot {
+		return errors.New("slot is older than the latest slot")
+	}
+	if _, present := c.index[hash]; present {
+		return errors.New("blockhash already present")
+	}
+	c.Advance(slot)
+	if c.r.Length() == c.r.Max() {
+		c.expireFirst()
+	}
+	id, err := c.r.Append(entry{hash: hash, slot: slot})
+	if err != nil {
+		return err
+	}
+	c.index[hash] = id
+	return nil
+}
+
+// move the latest slot forward and evict the blockhashes that have expired
+func (c *Cache) Advance(slot uint64) {
+	if slot < c.slot {
+		return
+	}
+	c.slot = slot
+	for 0 < c.r.Length() {
+		e, err := c.r.Get(0)
+		if err != nil || c.slot <= e.slot+c.maxAge {
+			return
+		}
+		c.expireFirst()
+	}
+}
+
+func (c *Cache) expireFirst() {
+	e, err := c.r.Pop()
+	if err != nil {
+		return
+	}
+	delete(c.index, e.hash)
+	for _, cb := range c.onExpire {
+		cb(e.hash, e.slot)
+	}
+}
+
+// returns true if the blockhash can still be used in a transaction
+func (c *Cache) IsValid(hash sgo.Hash) bool {
+	_, present := c.index[hash]
+	return present
+}
+
+// returns the last slot in which the blockhash is valid
+func (c *Cache) Expiry(hash sgo.Hash) (slot uint64, is_present bool) {
+	id, present := c.index[hash]
+	if !present {
+		return
+	}
+	e, err := c.r.GetById(id)
+	if err != nil {
+		return
+	}
+	slot = e.slot + c.maxAge
+	is_present = true
+	return
+}
+
+// returns the most recent blockhash
+func (c *Cache) Latest() (hash sgo.Hash, slot uint64, is_present bool) {
+	if c.r.Length() == 0 {
+		return
+	}
+	e, err := c.r.Get(c.r.Length() - 1)
+	if err != nil {
+		return
+	}
+	return e.hash, e.slot, true
+}
@@ -0,0 +1,80 @@
+package blockhash_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	sgo "github.com/SolmateDev/solana-go"
+	"github.com/solpipe/solpipe-util/ds/blockhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func hashOf(i uint64) sgo.Hash {
+	var h sgo.Hash
+	binary.BigEndian.PutUint64(h[:], i+1)
+	return h
+}
+
+func sigOf(i uint64) sgo.Signature {
+	var s sgo.Signature
+	binary.BigEndian.PutUint64(s[:], i+1)
+	return s
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c, err := blockhash.Create(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every other slot is skipped
+	for slot := uint64(100); slot < 140; slot += 2 {
+		assert.Nil(t, c.Add(slot, hashOf(slot)))
+	}
+	assert.NotNil(t, c.Add(120, hashOf(1000)), "slot went backwards")
+	assert.NotNil(t, c.Add(138, hashOf(138)), "duplicate blockhash")
+
+	// latest slot is 138, so slots 128..138 are valid
+	assert.Equal(t, uint(6), c.Length())
+	assert.False(t, c.IsValid(hashOf(126)))
+	assert.True(t, c.IsValid(hashOf(128)))
+	expiry, ok := c.Expiry(hashOf(128))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(138), expiry)
+
+	c.Advance(139)
+	assert.False(t, c.IsValid(hashOf(128)))
+	_, ok = c.Expiry(hashOf(128))
+	assert.False(t, ok)
+
+	hash, slot, ok := c.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, hashOf(138), hash)
+	assert.Equal(t, uint64(138), slot)
+}
+
+func TestSignatureSet(t *testing.T) {
+	c, err := blockhash.Create(blockhash.DEFAULT_MAX_AGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := blockhash.CreateSignatureSet(c)
+	assert.Nil(t, c.Add(1, hashOf(1)))
+	assert.Nil(t, c.Add(2, hashOf(2)))
+
+	isNew, err := s.Add(sigOf(0), hashOf(1))
+	assert.Nil(t, err)
+	assert.True(t, isNew)
+	isNew, err = s.Add(sigOf(0), hashOf(1))
+	assert.Nil(t, err)
+	assert.False(t, isNew, "duplicate signature")
+	_, err = s.Add(sigOf(1), hashOf(2))
+	assert.Nil(t, err)
+	_, err = s.Add(sigOf(2), hashOf(99))
+	assert.NotNil(t, err, "unknown blockhash")
+	assert.Equal(t, 2, s.Length())
+
+	c.Advance(1 + blockhash.DEFAULT_MAX_AGE + 1)
+	assert.False(t, s.Contains(sigOf(0)), "signature outlived its blockhash")
+	assert.True(t, s.Contains(sigOf(1)))
+	assert.Equal(t, 1, s.Length())
+}
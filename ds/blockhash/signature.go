@@ -0,0 +1,62 @@
+package blockhash
+
+import (
+	"errors"
+
+	sgo "github.com/SolmateDev/solana-go"
+)
+
+// SignatureSet deduplicates transaction signatures. Each signature is
+// filed under the blockhash of its transaction and is forgotten when that
+// blockhash expires from the Cache, since the transaction can no longer
+// land after that.
+//
+// SignatureSet is not safe for concurrent use.
+type SignatureSet struct {
+	cache  *Cache
+	bySig  map[sgo.Signature]sgo.Hash
+	byHash map[sgo.Hash][]sgo.Signature
+}
+
+func CreateSignatureSet(cache *Cache) *SignatureSet {
+	s := new(SignatureSet)
+	s.cache = cache
+	s.bySig = make(map[sgo.Signature]sgo.Hash)
+	s.byHash = make(map[sgo.Hash][]sgo.Signature)
+	cache.OnExpire(func(hash sgo.Hash, slot uint64) {
+		s.expire(hash)
+	})
+	return s
+}
+
+func (s *SignatureSet) expire(hash sgo.Hash) {
+	for _, sig := range s.byHash[hash] {
+		delete(s.bySig, sig)
+	}
+	delete(s.byHash, hash)
+}
+
+// returns the number of signatures in the set
+func (s *SignatureSet) Length() int {
+	return len(s.bySig)
+}
+
+// add a signature; isNew is false if it has been seen before.
+// an error is returned if the blockhash is not valid.
+func (s *SignatureSet) Add(sig sgo.Signature, blockhash sgo.Hash) (isNew bool, err error) {
+	if _, present := s.bySig[sig]; present {
+		return false, nil
+	}
+	if !s.cache.IsValid(blockhash) {
+		return false, errors.New("blockhash is not valid")
+	}
+	s.bySig[sig] = blockhash
+	s.byHash[blockhash] = append(s.byHash[blockhash], sig)
+	return true, nil
+}
+
+// returns true if the signature has been seen and has not expired
+func (s *SignatureSet) Contains(sig sgo.Signature) bool {
+	_, present := s.bySig[sig]
+	return present
+}
@@ -0,0 +1,367 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+)
+
+type Policy uint8
+
+const (
+	// evict the least recently used entry
+	POLICY_LRU Policy = 0
+	// evict the least frequently used entry, breaking ties by recency
+	POLICY_LFU Policy = 1
+)
+
+// default upper bound on a load started by GetOrLoad
+const DEFAULT_LOAD_TIMEOUT = 30 * time.Second
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+	freq      uint64
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// an in-flight load that other callers of GetOrLoad can wait on
+type call[V any] struct {
+	doneC chan struct{}
+	value V
+	err   error
+}
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Loads     uint64
+	Evictions uint64
+}
+
+// Cache is a capacity bounded key-value cache. Recency order is kept in
+// list.Generic, with one list per use count under POLICY_LFU, and a map
+// from key to list node so every operation is O(1).
+//
+// Cache is safe for concurrent use. Eviction callbacks run after the cache
+// lock is released, so they may call back into the cache.
+type Cache[K comparable, V any] struct {
+	mutex    *sync.Mutex
+	capacity int
+	policy   Policy
+	ttl      time.Duration
+	index    map[K]*ll.Node[*entry[K, V]]
+	// POLICY_LRU uses freq 0 only
+	buckets map[uint64]*ll.Generic[*entry[K, V]]
+	minFreq uint64
+	calls   map[K]*call[V]
+	// bounds loads, which outlive the context of the caller that started them
+	loadTimeout time.Duration
+	onEvict     []func(key K, value V)
+	stats       Stats
+}
+
+// create a cache holding at most capacity entries. A positive ttl is the
+// default lifetime of an entry; 0 means entries do not expire.
+func Create[K comparable, V any](capacity int, policy Policy, ttl time.Duration) (*Cache[K, V], error) {
+	if capacity <= 0 {
+		return nil, errors.New("capacity must be positive")
+	}
+	if policy != POLICY_LRU && policy != POLICY_LFU {
+		return nil, errors.New("unknown policy")
+	}
+	if ttl < 0 {
+		return nil, errors.New("ttl is negative")
+	}
+	c := new(Cache[K, V])
+	c.mutex = &sync.Mutex{}
+	c.capacity = capacity
+	c.policy = policy
+	c.ttl = ttl
+	c.index = make(map[K]*ll.Node[*entry[K, V]])
+	c.buckets = make(map[uint64]*ll.Generic[*entry[K, V]])
+	c.minFreq = 0
+	c.calls = make(map[K]*call[V])
+	c.loadTimeout = DEFAULT_LOAD_TIMEOUT
+	c.onEvict = make([]func(K, V), 0)
+	return c, nil
+}
+
+// set how long a load started by GetOrLoad may run before its context is
+// cancelled
+func (c *Cache[K, V]) SetLoadTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	c.mutex.Lock()
+	c.loadTimeout = timeout
+	c.mutex.Unlock()
+	return nil
+}
+
+// register a callback that is run for every entry that is evicted, expires
+// or is removed
+func (c *Cache[K, V]) OnEvict(callback func(key K, value V)) {
+	c.mutex.Lock()
+	c.onEvict = append(c.onEvict, callback)
+	c.mutex.Unlock()
+}
+
+func (c *Cache[K, V]) notify(evicted []*entry[K, V]) {
+	if len(evicted) == 0 {
+		return
+	}
+	c.mutex.Lock()
+	list := c.onEvict
+	c.mutex.Unlock()
+	for _, e := range evicted {
+		for _, cb := range list {
+			cb(e.key, e.value)
+		}
+	}
+}
+
+// returns the number of entries, including expired ones not yet removed
+func (c *Cache[K, V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.index)
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stats
+}
+
+func (c *Cache[K, V]) bucket(freq uint64) *ll.Generic[*entry[K, V]] {
+	b, present := c.buckets[freq]
+	if !present {
+		b = ll.CreateGeneric[*entry[K, V]]()
+		c.buckets[freq] = b
+	}
+	return b
+}
+
+// take the node out of its bucket; the mutex must be held
+func (c *Cache[K, V]) unlink(node *ll.Node[*entry[K, V]]) {
+	e := node.Value()
+	b := c.buckets[e.freq]
+	b.Remove(node)
+	if b.Size == 0 {
+		delete(c.buckets, e.freq)
+		if c.minFreq == e.freq {
+			c.minFreq++
+		}
+	}
+}
+
+// put the entry at the most recent end of its bucket; the mutex must be held
+func (c *Cache[K, V]) link(e *entry[K, V]) {
+	if len(c.index) == 0 || e.freq < c.minFreq {
+		c.minFreq = e.freq
+	}
+	c.index[e.key] = c.bucket(e.freq).Append(e)
+}
+
+// record a use of the entry; the mutex must be held
+func (c *Cache[K, V]) touch(node *ll.Node[*entry[K, V]]) {
+	e := node.Value()
+	delete(c.index, e.key)
+	c.unlink(node)
+	if c.policy == POLICY_LFU {
+		e.freq++
+	}
+	c.link(e)
+}
+
+// drop the entry; the mutex must be held
+func (c *Cache[K, V]) remove(node *ll.Node[*entry[K, V]]) *entry[K, V] {
+	e := node.Value()
+	delete(c.index, e.key)
+	c.unlink(node)
+	return e
+}
+
+// evict entries until there is room for one more; the mutex must be held
+func (c *Cache[K, V]) makeRoom() []*entry[K, V] {
+	evicted := make([]*entry[K, V], 0)
+	for c.capacity <= len(c.index) {
+		b, present := c.buckets[c.minFreq]
+		if !present {
+			// minFreq fell behind after removals; find the real minimum
+			first := true
+			for f := range c.buckets {
+				if first || f < c.minFreq {
+					c.minFreq = f
+					first = false
+				}
+			}
+			continue
+		}
+		evicted = append(evicted, c.remove(b.HeadNode()))
+		c.stats.Evictions++
+	}
+	return evicted
+}
+
+// look up the key, treating expired entries as missing; the mutex must be held
+func (c *Cache[K, V]) get(key K) (value V, is_present bool, expired *entry[K, V]) {
+	node, present := c.index[key]
+	if !present {
+		return
+	}
+	if node.Value().expired(time.Now()) {
+		expired = c.remove(node)
+		return
+	}
+	c.touch(node)
+	return node.Value().value, true, nil
+}
+
+// returns the value for key if present and not expired
+func (c *Cache[K, V]) Get(key K) (value V, is_present bool) {
+	c.mutex.Lock()
+	value, is_present, expired := c.get(key)
+	if is_present {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	c.mutex.Unlock()
+	if expired != nil {
+		c.notify([]*entry[K, V]{expired})
+	}
+	return
+}
+
+// store value under key with the default ttl
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// store value under key; a ttl of 0 means the entry does not expire
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mutex.Lock()
+	evicted := c.set(key, value, ttl)
+	c.mutex.Unlock()
+	c.notify(evicted)
+}
+
+// the mutex must be held
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) []*entry[K, V] {
+	var expiresAt time.Time
+	if 0 < ttl {
+		expiresAt = time.Now().Add(ttl)
+	}
+	if node, present := c.index[key]; present {
+		e := node.Value()
+		e.value = value
+		e.expiresAt = expiresAt
+		c.touch(node)
+		return nil
+	}
+	evicted := c.makeRoom()
+	var freq uint64 = 0
+	if c.policy == POLICY_LFU {
+		freq = 1
+	}
+	c.link(&entry[K, V]{key: key, value: value, expiresAt: expiresAt, freq: freq})
+	return evicted
+}
+
+// remove the entry for key; returns false if there was none
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mutex.Lock()
+	node, present := c.index[key]
+	var e *entry[K, V]
+	if present {
+		e = c.remove(node)
+	}
+	c.mutex.Unlock()
+	if e != nil {
+		c.notify([]*entry[K, V]{e})
+	}
+	return present
+}
+
+// remove every expired entry
+func (c *Cache[K, V]) RemoveExpired() int {
+	c.mutex.Lock()
+	now := time.Now()
+	expired := make([]*entry[K, V], 0)
+	for _, node := range c.index {
+		if node.Value().expired(now) {
+			expired = append(expired, c.remove(node))
+		}
+	}
+	c.mutex.Unlock()
+	c.notify(expired)
+	return len(expired)
+}
+
+// returns the value for key, calling loader on a miss. Concurrent misses
+// on the same key share a single call to loader. Errors are not cached.
+//
+// The loader runs on its own goroutine with a context that keeps the values
+// of ctx but is not cancelled with it; it is cancelled after the load
+// timeout instead (see SetLoadTimeout). A caller whose ctx is done stops
+// waiting without affecting the other callers. A panic in loader is
+// returned as an error to every caller.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (value V, err error) {
+	c.mutex.Lock()
+	value, is_present, expired := c.get(key)
+	if is_present {
+		c.stats.Hits++
+		c.mutex.Unlock()
+		return
+	}
+	c.stats.Misses++
+	cl, loading := c.calls[key]
+	if !loading {
+		cl = &call[V]{doneC: make(chan struct{})}
+		c.calls[key] = cl
+		c.stats.Loads++
+		go c.load(ctx, key, cl, loader, c.loadTimeout)
+	}
+	c.mutex.Unlock()
+	if expired != nil {
+		c.notify([]*entry[K, V]{expired})
+	}
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-cl.doneC:
+		value, err = cl.value, cl.err
+	}
+	return
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, cl *call[V], loader func(ctx context.Context, key K) (V, error), timeout time.Duration) {
+	defer func() {
+		if r := recover(); r != nil {
+			cl.err = fmt.Errorf("loader panicked: %v", r)
+		}
+		c.mutex.Lock()
+		delete(c.calls, key)
+		var evicted []*entry[K, V]
+		if cl.err == nil {
+			evicted = c.set(key, cl.value, c.ttl)
+		}
+		c.mutex.Unlock()
+		close(cl.doneC)
+		c.notify(evicted)
+	}()
+	loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	cl.value, cl.err = loader(loadCtx, key)
+}
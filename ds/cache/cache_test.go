@@ -0,0 +1,208 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sgo "github.com/SolmateDev/solana-go"
+	"github.com/solpipe/solpipe-util/ds/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c, err := cache.Create[string, int](2, cache.POLICY_LRU, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evicted := make([]string, 0)
+	c.OnEvict(func(key string, value int) {
+		evicted = append(evicted, key)
+	})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", 3)
+	assert.Equal(t, []string{"b"}, evicted, "evicted the wrong entry")
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	assert.True(t, c.Delete("a"))
+	assert.False(t, c.Delete("a"))
+	assert.Equal(t, []string{"b", "a"}, evicted)
+
+	s := c.Stats()
+	assert.Equal(t, uint64(1), s.Hits)
+	assert.Equal(t, uint64(1), s.Misses)
+	assert.Equal(t, uint64(1), s.Evictions)
+}
+
+func TestLFU(t *testing.T) {
+	c, err := cache.Create[sgo.PublicKey, int](3, cache.POLICY_LFU, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]sgo.PublicKey, 4)
+	for i := range keys {
+		keys[i][0] = byte(i)
+	}
+	c.Set(keys[0], 0)
+	c.Set(keys[1], 1)
+	c.Set(keys[2], 2)
+	// key 0 is used most, key 2 least; key 1 was used more recently than 2
+	for i := 0; i < 3; i++ {
+		c.Get(keys[0])
+	}
+	c.Get(keys[1])
+	c.Get(keys[2])
+	c.Get(keys[1])
+	c.Set(keys[3], 3)
+	_, ok := c.Get(keys[2])
+	assert.False(t, ok, "least frequently used entry was kept")
+	for _, i := range []int{0, 1, 3} {
+		_, ok = c.Get(keys[i])
+		assert.True(t, ok, "key %d", i)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c, err := cache.Create[string, int](10, cache.POLICY_LRU, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("short", 1)
+	c.SetWithTTL("forever", 2, 0)
+	c.SetWithTTL("long", 3, time.Hour)
+	time.Sleep(40 * time.Millisecond)
+	_, ok := c.Get("short")
+	assert.False(t, ok, "entry outlived its ttl")
+	_, ok = c.Get("forever")
+	assert.True(t, ok)
+	c.SetWithTTL("another", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, 1, c.RemoveExpired())
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestGetOrLoad(t *testing.T) {
+	c, err := cache.Create[string, int](10, cache.POLICY_LRU, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int32 = 0
+	releaseC := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-releaseC
+		return len(key), nil
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), "account", loader)
+			assert.Nil(t, err)
+			assert.Equal(t, 7, v)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(releaseC)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "loader ran more than once")
+	assert.Equal(t, uint64(1), c.Stats().Loads)
+
+	v, ok := c.Get("account")
+	assert.True(t, ok)
+	assert.Equal(t, 7, v)
+
+	failure := errors.New("rpc failed")
+	_, err = c.GetOrLoad(context.Background(), "bad", func(ctx context.Context, key string) (int, error) {
+		return 0, failure
+	})
+	assert.ErrorIs(t, err, failure)
+	_, ok = c.Get("bad")
+	assert.False(t, ok, "error was cached")
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c, err := cache.Create[string, int](10, cache.POLICY_LRU, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetOrLoad(context.Background(), "account", func(ctx context.Context, key string) (int, error) {
+		panic("rpc client is nil")
+	})
+	assert.NotNil(t, err)
+
+	// the failed load must not block the next one
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, err := c.GetOrLoad(ctx, "account", func(ctx context.Context, key string) (int, error) {
+		return 1, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestGetOrLoadCancel(t *testing.T) {
+	c, err := cache.Create[string, int](10, cache.POLICY_LRU, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startedC := make(chan struct{})
+	releaseC := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		close(startedC)
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-releaseC:
+			return 7, nil
+		}
+	}
+
+	// the caller that starts the load gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "account", loader)
+		errC <- err
+	}()
+	<-startedC
+	resultC := make(chan int, 1)
+	go func() {
+		v, err := c.GetOrLoad(context.Background(), "account", loader)
+		assert.Nil(t, err)
+		resultC <- v
+	}()
+	cancel()
+	assert.ErrorIs(t, <-errC, context.Canceled)
+
+	close(releaseC)
+	assert.Equal(t, 7, <-resultC, "cancelling the first caller failed the other")
+	assert.Equal(t, uint64(1), c.Stats().Loads)
+	v, ok := c.Get("account")
+	assert.True(t, ok)
+	assert.Equal(t, 7, v)
+}
+
+func TestGetOrLoadTimeout(t *testing.T) {
+	c, err := cache.Create[string, int](10, cache.POLICY_LRU, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotNil(t, c.SetLoadTimeout(0))
+	assert.Nil(t, c.SetLoadTimeout(10*time.Millisecond))
+	_, err = c.GetOrLoad(context.Background(), "account", func(ctx context.Context, key string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,185 @@
+package heap
+
+import (
+	"errors"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+)
+
+// Handle refers to an element in a Heap. It stays valid while the element
+// moves around the heap, so the element can be updated or removed later.
+type Handle[T any] struct {
+	value T
+	// position in the heap array, or -1 once removed
+	index int
+	heap  *Heap[T]
+}
+
+// returns the current value of the element
+func (h *Handle[T]) Value() T {
+	return h.value
+}
+
+// returns false once the element has been popped or removed
+func (h *Handle[T]) InHeap() bool {
+	return 0 <= h.index
+}
+
+// Heap is a binary heap priority queue. The element that compares lowest
+// is popped first; use CreateMax to pop the highest first.
+type Heap[T any] struct {
+	list    []*Handle[T]
+	compare ll.CompareCallback[T]
+}
+
+// create a heap that pops the lowest element first
+func Create[T any](compare ll.CompareCallback[T]) *Heap[T] {
+	h := new(Heap[T])
+	h.list = make([]*Handle[T], 0)
+	h.compare = compare
+	return h
+}
+
+// create a heap that pops the highest element first
+func CreateMax[T any](compare ll.CompareCallback[T]) *Heap[T] {
+	return Create(func(a T, b T) int {
+		return compare(b, a)
+	})
+}
+
+// returns the number of elements in the heap
+func (h *Heap[T]) Len() int {
+	return len(h.list)
+}
+
+func (h *Heap[T]) less(i int, j int) bool {
+	return h.compare(h.list[i].value, h.list[j].value) < 0
+}
+
+func (h *Heap[T]) swap(i int, j int) {
+	h.list[i], h.list[j] = h.list[j], h.list[i]
+	h.list[i].index = i
+	h.list[j].index = j
+}
+
+func (h *Heap[T]) up(j int) {
+	for 0 < j {
+		i := (j - 1) / 2
+		if !h.less(j, i) {
+			break
+		}
+		h.swap(i, j)
+		j = i
+	}
+}
+
+// returns true if the element moved
+func (h *Heap[T]) down(i0 int) bool {
+	i := i0
+	n := len(h.list)
+	for {
+		j := 2*i + 1
+		if n <= j {
+			break
+		}
+		if j2 := j + 1; j2 < n && h.less(j2, j) {
+			j = j2
+		}
+		if !h.less(j, i) {
+			break
+		}
+		h.swap(i, j)
+		i = j
+	}
+	return i0 < i
+}
+
+// restore the heap after the element at i changed
+func (h *Heap[T]) fix(i int) {
+	if !h.down(i) {
+		h.up(i)
+	}
+}
+
+// add an element in O(log n)
+func (h *Heap[T]) Push(v T) *Handle[T] {
+	x := &Handle[T]{value: v, index: len(h.list), heap: h}
+	h.list = append(h.list, x)
+	h.up(x.index)
+	return x
+}
+
+// returns the first element without removing it
+func (h *Heap[T]) Peek() (ans T, is_present bool) {
+	if len(h.list) == 0 {
+		return
+	}
+	return h.list[0].value, true
+}
+
+// remove and return the first element
+func (h *Heap[T]) Pop() (ans T, is_present bool) {
+	if len(h.list) == 0 {
+		return
+	}
+	return h.removeAt(0), true
+}
+
+// remove and return up to n elements in order; n <= 0 removes nothing
+func (h *Heap[T]) PopN(n int) []T {
+	if n < 0 {
+		n = 0
+	}
+	if len(h.list) < n {
+		n = len(h.list)
+	}
+	ans := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		ans = append(ans, h.removeAt(0))
+	}
+	return ans
+}
+
+func (h *Heap[T]) removeAt(i int) T {
+	last := len(h.list) - 1
+	x := h.list[i]
+	if i != last {
+		h.swap(i, last)
+	}
+	h.list[last] = nil
+	h.list = h.list[:last]
+	if i != last {
+		h.fix(i)
+	}
+	x.index = -1
+	return x.value
+}
+
+func (h *Heap[T]) check(x *Handle[T]) error {
+	if x == nil || x.heap != h {
+		return errors.New("handle does not belong to this heap")
+	}
+	if x.index < 0 {
+		return errors.New("element is no longer in the heap")
+	}
+	return nil
+}
+
+// change the value of an element and move it to its new place in O(log n)
+func (h *Heap[T]) Update(x *Handle[T], v T) error {
+	if err := h.check(x); err != nil {
+		return err
+	}
+	x.value = v
+	h.fix(x.index)
+	return nil
+}
+
+// remove an element in O(log n)
+func (h *Heap[T]) Remove(x *Handle[T]) error {
+	if err := h.check(x); err != nil {
+		return err
+	}
+	h.removeAt(x.index)
+	return nil
+}
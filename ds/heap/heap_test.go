@@ -0,0 +1,79 @@
+package heap_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/heap"
+	"github.com/stretchr/testify/assert"
+)
+
+type pendingTx struct {
+	id  int
+	fee uint64
+}
+
+func compareFee(a pendingTx, b pendingTx) int {
+	if a.fee < b.fee {
+		return -1
+	} else if a.fee == b.fee {
+		return 0
+	} else {
+		return 1
+	}
+}
+
+func TestHeapUpdateRemove(t *testing.T) {
+	h := heap.CreateMax(compareFee)
+	rng := rand.New(rand.NewSource(1))
+	handles := make([]*heap.Handle[pendingTx], 200)
+	for i := range handles {
+		handles[i] = h.Push(pendingTx{id: i, fee: uint64(rng.Intn(1000))})
+	}
+	// the fee market moves: re-prioritize some, drop others
+	for i := 0; i < len(handles); i += 3 {
+		assert.Nil(t, h.Update(handles[i], pendingTx{id: i, fee: uint64(rng.Intn(1000))}))
+	}
+	for i := 1; i < len(handles); i += 5 {
+		assert.Nil(t, h.Remove(handles[i]))
+		assert.False(t, handles[i].InHeap())
+	}
+	assert.NotNil(t, h.Remove(handles[1]), "removed twice")
+	assert.NotNil(t, h.Update(handles[1], pendingTx{}), "updated a removed element")
+
+	other := heap.Create(compareFee)
+	assert.NotNil(t, other.Remove(handles[0]), "removed through another heap")
+
+	expected := make([]uint64, 0)
+	for _, x := range handles {
+		if x.InHeap() {
+			expected = append(expected, x.Value().fee)
+		}
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i] > expected[j] })
+	assert.Equal(t, len(expected), h.Len())
+
+	top, ok := h.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, expected[0], top.fee)
+
+	assert.Empty(t, h.PopN(0))
+	assert.Empty(t, h.PopN(-1))
+	assert.Equal(t, len(expected), h.Len())
+
+	batch := h.PopN(10)
+	got := make([]uint64, 0)
+	for _, x := range batch {
+		got = append(got, x.fee)
+	}
+	for {
+		x, ok := h.Pop()
+		if !ok {
+			break
+		}
+		got = append(got, x.fee)
+	}
+	assert.Equal(t, expected, got)
+	assert.Empty(t, h.PopN(5))
+}
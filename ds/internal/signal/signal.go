@@ -0,0 +1,28 @@
+// Package signal wakes up goroutines waiting for a change to state that is
+// guarded by the caller's mutex, in a way that can be combined with a
+// context in a select.
+package signal
+
+// Signal is a broadcast channel that is only allocated while someone is
+// waiting, so notifying with no waiters costs nothing. The zero value is
+// ready to use. All methods must be called with the caller's mutex held.
+type Signal struct {
+	changeC chan struct{}
+}
+
+// returns a channel that is closed by the next Broadcast
+func (s *Signal) Wait() <-chan struct{} {
+	if s.changeC == nil {
+		s.changeC = make(chan struct{})
+	}
+	return s.changeC
+}
+
+// wake up every goroutine waiting on a channel from Wait
+func (s *Signal) Broadcast() {
+	if s.changeC == nil {
+		return
+	}
+	close(s.changeC)
+	s.changeC = nil
+}
@@ -0,0 +1,192 @@
+package keyset
+
+import (
+	"crypto/sha256"
+	"sort"
+
+	sgo "github.com/SolmateDev/solana-go"
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/solpipe/solpipe-util/ds/mr"
+)
+
+// Set is an ordered set of public keys backed by a sorted slice.
+// Lookups and Rank are O(log n), Add and Remove are O(n), and the set
+// algebra runs in linear time by merging the two sorted slices.
+// Iteration is always in ascending key order, so two sets with the same
+// members produce the same Hash and MerkleRoot.
+type Set struct {
+	list []sgo.PublicKey
+}
+
+func Create() *Set {
+	return &Set{list: make([]sgo.PublicKey, 0)}
+}
+
+// create a set from keys in any order; duplicates are dropped
+func CreateFromList(keys []sgo.PublicKey) *Set {
+	list := make([]sgo.PublicKey, len(keys))
+	copy(list, keys)
+	sort.Slice(list, func(i, j int) bool {
+		return ll.ComparePublicKey(list[i], list[j]) < 0
+	})
+	s := Create()
+	for _, k := range list {
+		if len(s.list) == 0 || !s.list[len(s.list)-1].Equals(k) {
+			s.list = append(s.list, k)
+		}
+	}
+	return s
+}
+
+// returns the number of keys in the set
+func (s *Set) Len() int {
+	return len(s.list)
+}
+
+// returns the number of keys less than key, which is the index key has
+// or would have in the set
+func (s *Set) Rank(key sgo.PublicKey) int {
+	return sort.Search(len(s.list), func(i int) bool {
+		return 0 <= ll.ComparePublicKey(s.list[i], key)
+	})
+}
+
+func (s *Set) Contains(key sgo.PublicKey) bool {
+	i := s.Rank(key)
+	return i < len(s.list) && s.list[i].Equals(key)
+}
+
+// add key; returns false if it was already present
+func (s *Set) Add(key sgo.PublicKey) bool {
+	i := s.Rank(key)
+	if i < len(s.list) && s.list[i].Equals(key) {
+		return false
+	}
+	s.list = append(s.list, sgo.PublicKey{})
+	copy(s.list[i+1:], s.list[i:])
+	s.list[i] = key
+	return true
+}
+
+// remove key; returns false if it was not present
+func (s *Set) Remove(key sgo.PublicKey) bool {
+	i := s.Rank(key)
+	if len(s.list) <= i || !s.list[i].Equals(key) {
+		return false
+	}
+	copy(s.list[i:], s.list[i+1:])
+	s.list = s.list[:len(s.list)-1]
+	return true
+}
+
+// returns the Ith smallest key
+func (s *Set) Get(i int) (ans sgo.PublicKey, is_present bool) {
+	if i < 0 || len(s.list) <= i {
+		return
+	}
+	return s.list[i], true
+}
+
+// returns a copy of the keys in ascending order
+func (s *Set) Array() []sgo.PublicKey {
+	ans := make([]sgo.PublicKey, len(s.list))
+	copy(ans, s.list)
+	return ans
+}
+
+// walk the keys in ascending order, stopping at the first error
+func (s *Set) Iterate(callback func(key sgo.PublicKey, index int) error) error {
+	for i, k := range s.list {
+		if err := callback(k, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge walks both sets in order; keep decides from which sides a key
+// is kept: inA, inB
+func merge(a *Set, b *Set, keep func(inA bool, inB bool) bool) *Set {
+	ans := Create()
+	i, j := 0, 0
+	for i < len(a.list) || j < len(b.list) {
+		var c int
+		if i == len(a.list) {
+			c = 1
+		} else if j == len(b.list) {
+			c = -1
+		} else {
+			c = ll.ComparePublicKey(a.list[i], b.list[j])
+		}
+		switch {
+		case c < 0:
+			if keep(true, false) {
+				ans.list = append(ans.list, a.list[i])
+			}
+			i++
+		case 0 < c:
+			if keep(false, true) {
+				ans.list = append(ans.list, b.list[j])
+			}
+			j++
+		default:
+			if keep(true, true) {
+				ans.list = append(ans.list, a.list[i])
+			}
+			i++
+			j++
+		}
+	}
+	return ans
+}
+
+// returns the keys in either set
+func (s *Set) Union(other *Set) *Set {
+	return merge(s, other, func(inA bool, inB bool) bool { return true })
+}
+
+// returns the keys in both sets
+func (s *Set) Intersect(other *Set) *Set {
+	return merge(s, other, func(inA bool, inB bool) bool { return inA && inB })
+}
+
+// returns the keys in this set but not in other
+func (s *Set) Difference(other *Set) *Set {
+	return merge(s, other, func(inA bool, inB bool) bool { return inA && !inB })
+}
+
+// returns true if both sets have the same members
+func (s *Set) Equals(other *Set) bool {
+	if len(s.list) != len(other.list) {
+		return false
+	}
+	for i := range s.list {
+		if !s.list[i].Equals(other.list[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the sha256 of the keys concatenated in ascending order
+func (s *Set) Hash() sgo.Hash {
+	h := sha256.New()
+	for _, k := range s.list {
+		h.Write(k[:])
+	}
+	return sgo.HashFromBytes(h.Sum(nil))
+}
+
+// returns the root of a merkle tree over the keys in ascending order.
+// The set must not be empty.
+func (s *Set) MerkleRoot() (sgo.Hash, error) {
+	hashList := make([]sgo.Hash, len(s.list))
+	for i, k := range s.list {
+		hashList[i] = sgo.Hash(k)
+	}
+	tree, err := mr.Create(hashList)
+	if err != nil {
+		return sgo.Hash{}, err
+	}
+	return tree.Root(), nil
+}
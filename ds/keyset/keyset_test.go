@@ -0,0 +1,75 @@
+package keyset_test
+
+import (
+	"testing"
+
+	sgo "github.com/SolmateDev/solana-go"
+	"github.com/solpipe/solpipe-util/ds/keyset"
+	"github.com/stretchr/testify/assert"
+)
+
+func key(b byte) sgo.PublicKey {
+	var k sgo.PublicKey
+	k[0] = b
+	return k
+}
+
+func keys(list ...byte) []sgo.PublicKey {
+	ans := make([]sgo.PublicKey, len(list))
+	for i, b := range list {
+		ans[i] = key(b)
+	}
+	return ans
+}
+
+func TestSetBasics(t *testing.T) {
+	s := keyset.CreateFromList(keys(5, 1, 3, 1, 9))
+	assert.Equal(t, keys(1, 3, 5, 9), s.Array())
+
+	assert.True(t, s.Add(key(4)))
+	assert.False(t, s.Add(key(4)))
+	assert.True(t, s.Contains(key(4)))
+	assert.False(t, s.Contains(key(2)))
+	assert.Equal(t, 2, s.Rank(key(4)))
+	assert.Equal(t, 1, s.Rank(key(2)))
+	assert.Equal(t, 5, s.Rank(key(10)))
+
+	assert.True(t, s.Remove(key(1)))
+	assert.False(t, s.Remove(key(1)))
+	assert.Equal(t, keys(3, 4, 5, 9), s.Array())
+	k, ok := s.Get(0)
+	assert.True(t, ok)
+	assert.Equal(t, key(3), k)
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := keyset.CreateFromList(keys(1, 2, 3, 5, 8))
+	b := keyset.CreateFromList(keys(2, 3, 4, 8, 13))
+	assert.Equal(t, keys(1, 2, 3, 4, 5, 8, 13), a.Union(b).Array())
+	assert.Equal(t, keys(2, 3, 8), a.Intersect(b).Array())
+	assert.Equal(t, keys(1, 5), a.Difference(b).Array())
+	assert.Equal(t, keys(4, 13), b.Difference(a).Array())
+	assert.Equal(t, 0, a.Intersect(keyset.Create()).Len())
+}
+
+func TestSetCommitment(t *testing.T) {
+	a := keyset.CreateFromList(keys(7, 2, 9))
+	b := keyset.Create()
+	for _, k := range keys(9, 7, 2) {
+		b.Add(k)
+	}
+	assert.True(t, a.Equals(b))
+	assert.Equal(t, a.Hash(), b.Hash(), "insertion order changed the hash")
+	rootA, err := a.MerkleRoot()
+	assert.Nil(t, err)
+	rootB, err := b.MerkleRoot()
+	assert.Nil(t, err)
+	assert.Equal(t, rootA, rootB)
+
+	b.Remove(key(7))
+	assert.False(t, a.Equals(b))
+	assert.NotEqual(t, a.Hash(), b.Hash())
+
+	_, err = keyset.Create().MerkleRoot()
+	assert.NotNil(t, err)
+}
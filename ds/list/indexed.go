@@ -0,0 +1,339 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/solpipe/solpipe-util/ds/radix"
+)
+
+type IndexKind uint8
+
+const (
+	// map from key to nodes
+	INDEX_HASH IndexKind = 0
+	// radix tree from key to nodes; supports prefix walks
+	INDEX_RADIX IndexKind = 1
+)
+
+type keyIndex[T any] interface {
+	get(key string) []*Node[T]
+	add(key string, node *Node[T])
+	remove(key string, node *Node[T])
+}
+
+type hashIndex[T any] struct {
+	m map[string][]*Node[T]
+}
+
+func (h *hashIndex[T]) get(key string) []*Node[T] {
+	return h.m[key]
+}
+
+func (h *hashIndex[T]) add(key string, node *Node[T]) {
+	h.m[key] = append(h.m[key], node)
+}
+
+func (h *hashIndex[T]) remove(key string, node *Node[T]) {
+	list := removeNode(h.m[key], node)
+	if len(list) == 0 {
+		delete(h.m, key)
+	} else {
+		h.m[key] = list
+	}
+}
+
+type radixIndex[T any] struct {
+	t *radix.Tree[[]*Node[T]]
+}
+
+func (r *radixIndex[T]) get(key string) []*Node[T] {
+	list, _ := r.t.Get(key)
+	return list
+}
+
+func (r *radixIndex[T]) add(key string, node *Node[T]) {
+	list, _ := r.t.Get(key)
+	r.t.Insert(key, append(list, node))
+}
+
+func (r *radixIndex[T]) remove(key string, node *Node[T]) {
+	list, present := r.t.Get(key)
+	if !present {
+		return
+	}
+	list = removeNode(list, node)
+	if len(list) == 0 {
+		r.t.Delete(key)
+	} else {
+		r.t.Insert(key, list)
+	}
+}
+
+func removeNode[T any](list []*Node[T], node *Node[T]) []*Node[T] {
+	for i, x := range list {
+		if x == node {
+			copy(list[i:], list[i+1:])
+			list[len(list)-1] = nil
+			return list[:len(list)-1]
+		}
+	}
+	return list
+}
+
+type indexSpec[T any] struct {
+	getKey func(T) string
+	unique bool
+	kind   IndexKind
+	index  keyIndex[T]
+	// the key each node is filed under, so that it can be found again
+	// after the value has changed
+	keys map[*Node[T]]string
+}
+
+func (spec *indexSpec[T]) add(node *Node[T]) {
+	key := spec.getKey(node.value)
+	spec.keys[node] = key
+	spec.index.add(key, node)
+}
+
+func (spec *indexSpec[T]) remove(node *Node[T]) {
+	key, present := spec.keys[node]
+	if !present {
+		return
+	}
+	delete(spec.keys, node)
+	spec.index.remove(key, node)
+}
+
+// Indexed is a Generic list that keeps one or more key indexes in sync with
+// its contents. Unlike Generic.RadixIndex, the indexes are updated on every
+// mutation. Calling ChangeValue on a node re-indexes it as well, but cannot
+// fail, so unique indexes are only enforced by Indexed.ChangeValue.
+type Indexed[T any] struct {
+	g       *Generic[T]
+	indexes map[string]*indexSpec[T]
+}
+
+func CreateIndexed[T any]() *Indexed[T] {
+	l := &Indexed[T]{g: CreateGeneric[T](), indexes: make(map[string]*indexSpec[T])}
+	l.g.AddObserver(indexUpdater[T]{l})
+	return l
+}
+
+// moves nodes between index keys when Node.ChangeValue is called
+type indexUpdater[T any] struct {
+	l *Indexed[T]
+}
+
+// Indexed adds nodes to the indexes itself, after the unique check
+func (u indexUpdater[T]) Inserted(node *Node[T]) {}
+
+func (u indexUpdater[T]) Removed(value T) {}
+
+func (u indexUpdater[T]) Updated(node *Node[T], oldValue T, newValue T) {
+	u.l.removeFromIndexes(node)
+	u.l.addToIndexes(node)
+}
+
+// register an index under name. The index is built from the current
+// contents; if unique is set and two elements share a key, an error is
+// returned and the index is not added.
+func (l *Indexed[T]) AddIndex(name string, kind IndexKind, unique bool, getKey func(T) string) error {
+	if _, present := l.indexes[name]; present {
+		return fmt.Errorf("index %s already exists", name)
+	}
+	spec := &indexSpec[T]{getKey: getKey, unique: unique, kind: kind, keys: make(map[*Node[T]]string)}
+	switch kind {
+	case INDEX_HASH:
+		spec.index = &hashIndex[T]{m: make(map[string][]*Node[T])}
+	case INDEX_RADIX:
+		spec.index = &radixIndex[T]{t: radix.New[[]*Node[T]]()}
+	default:
+		return errors.New("unknown index kind")
+	}
+	for node := l.g.HeadNode(); node != nil; node = node.Next() {
+		key := getKey(node.Value())
+		if unique && 0 < len(spec.index.get(key)) {
+			return fmt.Errorf("duplicate key %s in unique index %s", key, name)
+		}
+		spec.keys[node] = key
+		spec.index.add(key, node)
+	}
+	l.indexes[name] = spec
+	return nil
+}
+
+// check v can be added to every unique index; self is allowed to hold the key
+func (l *Indexed[T]) checkUnique(v T, self *Node[T]) error {
+	for name, spec := range l.indexes {
+		if !spec.unique {
+			continue
+		}
+		key := spec.getKey(v)
+		for _, x := range spec.index.get(key) {
+			if x != self {
+				return fmt.Errorf("duplicate key %s in unique index %s", key, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Indexed[T]) addToIndexes(node *Node[T]) {
+	for _, spec := range l.indexes {
+		spec.add(node)
+	}
+}
+
+// removes node from the keys it was filed under, whatever its value is now
+func (l *Indexed[T]) removeFromIndexes(node *Node[T]) {
+	for _, spec := range l.indexes {
+		spec.remove(node)
+	}
+}
+
+// returns the number of elements in the list
+func (l *Indexed[T]) Len() uint32 {
+	return l.g.Size
+}
+
+func (l *Indexed[T]) HeadNode() *Node[T] {
+	return l.g.HeadNode()
+}
+
+func (l *Indexed[T]) TailNode() *Node[T] {
+	return l.g.TailNode()
+}
+
+func (l *Indexed[T]) Array() []T {
+	return l.g.Array()
+}
+
+// attach v to the end of the list
+func (l *Indexed[T]) Append(v T) (*Node[T], error) {
+	if err := l.checkUnique(v, nil); err != nil {
+		return nil, err
+	}
+	node := l.g.Append(v)
+	l.addToIndexes(node)
+	return node, nil
+}
+
+// attach v to the start of the list
+func (l *Indexed[T]) Prepend(v T) (*Node[T], error) {
+	if err := l.checkUnique(v, nil); err != nil {
+		return nil, err
+	}
+	node := l.g.Prepend(v)
+	l.addToIndexes(node)
+	return node, nil
+}
+
+// insert v after prevNode
+func (l *Indexed[T]) Insert(v T, prevNode *Node[T]) (*Node[T], error) {
+	if err := l.checkUnique(v, nil); err != nil {
+		return nil, err
+	}
+	node, err := l.g.Insert(v, prevNode)
+	if err != nil {
+		return nil, err
+	}
+	l.addToIndexes(node)
+	return node, nil
+}
+
+func (l *Indexed[T]) Remove(node *Node[T]) error {
+	if err := l.g.checkNode(node); err != nil {
+		return err
+	}
+	l.removeFromIndexes(node)
+	return l.g.Remove(node)
+}
+
+// remove and return the first element of the list
+func (l *Indexed[T]) Pop() (ans T, is_present bool) {
+	head := l.g.HeadNode()
+	if head == nil {
+		return
+	}
+	ans = head.Value()
+	l.Remove(head)
+	is_present = true
+	return
+}
+
+// replace the value of node, moving it between index keys as needed
+func (l *Indexed[T]) ChangeValue(node *Node[T], v T) error {
+	if err := l.g.checkNode(node); err != nil {
+		return err
+	}
+	if err := l.checkUnique(v, node); err != nil {
+		return err
+	}
+	// indexUpdater moves the node to its new keys
+	node.ChangeValue(v)
+	return nil
+}
+
+func (l *Indexed[T]) Iterate(callback func(obj T, index uint32, deleteNode func()) error) error {
+	var i uint32 = 0
+	var err error
+	deleteList := make([]*Node[T], 0)
+	for node := l.g.HeadNode(); node != nil; node = node.Next() {
+		n := node
+		// do not remove nodes until the iteration is complete
+		err = callback(n.Value(), i, func() { deleteList = append(deleteList, n) })
+		if err != nil {
+			return err
+		}
+		i++
+	}
+	for _, node := range deleteList {
+		l.Remove(node)
+	}
+	return nil
+}
+
+// returns the first node with the key in the named index, or nil
+func (l *Indexed[T]) GetByKey(name string, key string) (*Node[T], error) {
+	list, err := l.GetAllByKey(name, key)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// returns every node with the key in the named index, in insertion order
+func (l *Indexed[T]) GetAllByKey(name string, key string) ([]*Node[T], error) {
+	spec, present := l.indexes[name]
+	if !present {
+		return nil, fmt.Errorf("no index %s", name)
+	}
+	list := spec.index.get(key)
+	ans := make([]*Node[T], len(list))
+	copy(ans, list)
+	return ans, nil
+}
+
+// walk the nodes whose key in the named radix index starts with prefix.
+// Return true from fn to stop the walk.
+func (l *Indexed[T]) WalkPrefix(name string, prefix string, fn func(key string, node *Node[T]) bool) error {
+	spec, present := l.indexes[name]
+	if !present {
+		return fmt.Errorf("no index %s", name)
+	}
+	r, ok := spec.index.(*radixIndex[T])
+	if !ok {
+		return fmt.Errorf("index %s is not a radix index", name)
+	}
+	r.t.WalkPrefix(prefix, func(key string, list []*Node[T]) bool {
+		for _, node := range list {
+			if fn(key, node) {
+				return true
+			}
+		}
+		return false
+	})
+	return nil
+}
@@ -0,0 +1,116 @@
+package list_test
+
+import (
+	"strconv"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+type pipeline struct {
+	id    int
+	owner string
+}
+
+func TestIndexed(t *testing.T) {
+	l := ll.CreateIndexed[pipeline]()
+	_, err := l.Append(pipeline{id: 1, owner: "alice"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, l.AddIndex("id", ll.INDEX_HASH, true, func(p pipeline) string {
+		return strconv.Itoa(p.id)
+	}))
+	assert.Nil(t, l.AddIndex("owner", ll.INDEX_RADIX, false, func(p pipeline) string {
+		return p.owner
+	}))
+	assert.NotNil(t, l.AddIndex("owner", ll.INDEX_HASH, false, func(p pipeline) string {
+		return p.owner
+	}), "index added twice")
+
+	n2, err := l.Append(pipeline{id: 2, owner: "bob"})
+	assert.Nil(t, err)
+	_, err = l.Prepend(pipeline{id: 3, owner: "alice"})
+	assert.Nil(t, err)
+	_, err = l.Insert(pipeline{id: 4, owner: "albert"}, n2)
+	assert.Nil(t, err)
+	_, err = l.Append(pipeline{id: 2, owner: "carol"})
+	assert.NotNil(t, err, "unique index accepted a duplicate")
+	assert.Equal(t, uint32(4), l.Len())
+
+	node, err := l.GetByKey("id", "4")
+	assert.Nil(t, err)
+	assert.Equal(t, "albert", node.Value().owner)
+	list, err := l.GetAllByKey("owner", "alice")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	_, err = l.GetByKey("price", "1")
+	assert.NotNil(t, err)
+
+	prefixed := make([]int, 0)
+	assert.Nil(t, l.WalkPrefix("owner", "al", func(key string, node *ll.Node[pipeline]) bool {
+		prefixed = append(prefixed, node.Value().id)
+		return false
+	}))
+	assert.ElementsMatch(t, []int{1, 3, 4}, prefixed)
+	assert.NotNil(t, l.WalkPrefix("id", "1", func(key string, node *ll.Node[pipeline]) bool {
+		return false
+	}), "prefix walk over a hash index")
+
+	// moving a node between keys
+	assert.NotNil(t, l.ChangeValue(n2, pipeline{id: 1, owner: "bob"}), "changed onto a taken key")
+	assert.Nil(t, l.ChangeValue(n2, pipeline{id: 2, owner: "dave"}))
+	list, _ = l.GetAllByKey("owner", "bob")
+	assert.Empty(t, list)
+	node, _ = l.GetByKey("owner", "dave")
+	assert.Equal(t, n2, node)
+
+	assert.Nil(t, l.Iterate(func(obj pipeline, index uint32, deleteNode func()) error {
+		if obj.owner == "alice" {
+			deleteNode()
+		}
+		return nil
+	}))
+	list, _ = l.GetAllByKey("owner", "alice")
+	assert.Empty(t, list)
+	node, _ = l.GetByKey("id", "1")
+	assert.Nil(t, node)
+
+	v, ok := l.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v.id)
+	node, _ = l.GetByKey("id", "2")
+	assert.Nil(t, node)
+	assert.Equal(t, uint32(1), l.Len())
+
+	// the freed key can be reused
+	_, err = l.Append(pipeline{id: 1, owner: "erin"})
+	assert.Nil(t, err)
+}
+
+func TestIndexedNodeChangeValue(t *testing.T) {
+	l := ll.CreateIndexed[pipeline]()
+	assert.Nil(t, l.AddIndex("owner", ll.INDEX_HASH, true, func(p pipeline) string {
+		return p.owner
+	}))
+	node, err := l.Append(pipeline{id: 1, owner: "a"})
+	assert.Nil(t, err)
+
+	// bypass Indexed and change the node directly
+	node.ChangeValue(pipeline{id: 1, owner: "b"})
+	found, err := l.GetByKey("owner", "b")
+	assert.Nil(t, err)
+	assert.Equal(t, node, found)
+	found, err = l.GetByKey("owner", "a")
+	assert.Nil(t, err)
+	assert.Nil(t, found, "node left under its old key")
+
+	assert.Nil(t, l.Remove(node))
+	found, err = l.GetByKey("owner", "b")
+	assert.Nil(t, err)
+	assert.Nil(t, found, "removed node left in the index")
+	_, err = l.Append(pipeline{id: 2, owner: "a"})
+	assert.Nil(t, err, "stale key blocked a unique insert")
+	_, err = l.Append(pipeline{id: 3, owner: "b"})
+	assert.Nil(t, err, "stale key blocked a unique insert")
+}
@@ -0,0 +1,164 @@
+package list
+
+import "iter"
+
+// All yields the index and value of every element from head to tail.
+// The loop body may insert and remove nodes: the walk continues from the
+// live links of the current node or, if the current node was removed, from
+// its old neighbours. It stops early if those are gone as well.
+func (g *Generic[T]) All() iter.Seq2[uint32, T] {
+	return func(yield func(uint32, T) bool) {
+		var i uint32 = 0
+		for node := g.head; node != nil; {
+			prev, next := node.prev, node.next
+			if !yield(i, node.value) {
+				return
+			}
+			i++
+			node = g.after(node, prev, next)
+		}
+	}
+}
+
+// returns the node that follows node, given its neighbours from before the
+// loop body ran
+func (g *Generic[T]) after(node *Node[T], prev *Node[T], next *Node[T]) *Node[T] {
+	if node.list == g {
+		return node.next
+	}
+	if next != nil && next.list == g {
+		return next
+	}
+	if prev != nil && prev.list == g {
+		return prev.next
+	}
+	return nil
+}
+
+// returns the node that precedes node; see after
+func (g *Generic[T]) before(node *Node[T], prev *Node[T], next *Node[T]) *Node[T] {
+	if node.list == g {
+		return node.prev
+	}
+	if prev != nil && prev.list == g {
+		return prev
+	}
+	if next != nil && next.list == g {
+		return next.prev
+	}
+	return nil
+}
+
+// Values yields every element from head to tail
+func (g *Generic[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range g.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward yields the index and value of every element from tail to head.
+// The loop body may insert and remove nodes as in All; the index counts
+// down from the size the list had when the loop started.
+func (g *Generic[T]) Backward() iter.Seq2[uint32, T] {
+	return func(yield func(uint32, T) bool) {
+		i := g.Size - 1
+		for node := g.tail; node != nil; {
+			prev, next := node.prev, node.next
+			if !yield(i, node.value) {
+				return
+			}
+			i--
+			node = g.before(node, prev, next)
+		}
+	}
+}
+
+// All yields the index and value of every element in a snapshot of the
+// list taken when the loop starts. No lock is held while the loop body
+// runs, so it may call back into the list.
+func (g *SyncList[T]) All() iter.Seq2[uint32, T] {
+	return func(yield func(uint32, T) bool) {
+		for i, node := range g.snapshot() {
+			if !yield(uint32(i), node.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields every element in a snapshot of the list
+func (g *SyncList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, node := range g.snapshot() {
+			if !yield(node.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// All yields the index and value of every element from head to tail.
+// The list must not be modified while the loop runs.
+func (u *Unrolled[T]) All() iter.Seq2[uint32, T] {
+	return func(yield func(uint32, T) bool) {
+		var i uint32 = 0
+		for node := u.head; node != nil; node = node.next {
+			for k := 0; k < node.n; k++ {
+				if !yield(i, node.items[k]) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// Values yields every element from head to tail
+func (u *Unrolled[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := u.head; node != nil; node = node.next {
+			for k := 0; k < node.n; k++ {
+				if !yield(node.items[k]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Values yields every element in order
+func (s *Sorted[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := s.head.next[0]; node != nil; node = node.next[0] {
+			if !yield(node.value) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesFrom yields the elements not less than x in order
+func (s *Sorted[T]) ValuesFrom(x T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := s.before(x, nil).next[0]; node != nil; node = node.next[0] {
+			if !yield(node.value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward yields every element in reverse order
+func (s *Sorted[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := s.tail; node != nil; node = node.prev {
+			if !yield(node.value) {
+				return
+			}
+		}
+	}
+}
@@ -2,15 +2,17 @@ package list
 
 import (
 	"errors"
+	"fmt"
 
 	sgo "github.com/SolmateDev/solana-go"
 	"github.com/solpipe/solpipe-util/ds/radix"
 )
 
 type Generic[T any] struct {
-	Size uint32
-	head *Node[T]
-	tail *Node[T]
+	Size      uint32
+	head      *Node[T]
+	tail      *Node[T]
+	observers []*observerEntry[Observer[T]]
 }
 
 func CreateGeneric[T any]() *Generic[T] {
@@ -33,7 +35,7 @@ func (g *Generic[T]) AppendArray(a []T) *Node[T] {
 // attach obj to the end of a linked list
 func (g *Generic[T]) Append(obj T) *Node[T] {
 	g.Size++
-	node := &Node[T]{next: nil, prev: nil, value: obj}
+	node := &Node[T]{next: nil, prev: nil, list: g, value: obj}
 	if g.tail == nil {
 		g.head = node
 		g.tail = node
@@ -43,18 +45,22 @@ func (g *Generic[T]) Append(obj T) *Node[T] {
 		oldTail.next = node
 		g.tail = node
 	}
+	g.notifyInserted(node)
 	return node
 }
 
 func (g *Generic[T]) Prepend(obj T) *Node[T] {
 	g.Size++
-	node := &Node[T]{next: nil, prev: nil, value: obj}
+	node := &Node[T]{next: nil, prev: nil, list: g, value: obj}
 	oldHead := g.head
 	node.next = oldHead
 	g.head = node
 	if oldHead != nil {
 		oldHead.prev = node
+	} else {
+		g.tail = node
 	}
+	g.notifyInserted(node)
 	return node
 }
 
@@ -136,7 +142,8 @@ func (g *Generic[T]) InsertSorted(x T, compare CompareCallback[T]) *Node[T] {
 		}
 
 		if compare(node.Value(), x) <= 0 && compare(x, nextNode.Value()) <= 0 {
-			return g.Insert(x, node)
+			ans, _ := g.Insert(x, node)
+			return ans
 		}
 		i++
 	}
@@ -162,7 +169,10 @@ func ComparePublicKey(a sgo.PublicKey, b sgo.PublicKey) int {
 func (g *Generic[T]) IterateReverse(callback func(obj T, index uint32, delete func()) error) error {
 	var i uint32 = g.Size - 1
 	var err error
-	for node := g.tail; node != nil; node = node.prev {
+	var prev *Node[T]
+	for node := g.tail; node != nil; node = prev {
+		// Remove detaches the node, so step back before the callback runs
+		prev = node.prev
 		err = callback(node.value, i, func() {
 			g.Remove(node)
 		})
@@ -183,9 +193,24 @@ func (g *Generic[T]) Array() []T {
 	return ans
 }
 
-func (g *Generic[T]) Remove(node *Node[T]) {
+// returns an error if the node belongs to another list or has already
+// been removed
+func (g *Generic[T]) checkNode(node *Node[T]) error {
 	if node == nil {
-		return
+		return errors.New("node is nil")
+	}
+	if node.list == nil {
+		return errors.New("node has been removed")
+	}
+	if node.list != g {
+		return errors.New("node belongs to another list")
+	}
+	return nil
+}
+
+func (g *Generic[T]) Remove(node *Node[T]) error {
+	if err := g.checkNode(node); err != nil {
+		return err
 	}
 	prevNode := node.prev
 	nextNode := node.next
@@ -206,23 +231,31 @@ func (g *Generic[T]) Remove(node *Node[T]) {
 		prevNode.next = nextNode
 		nextNode.prev = prevNode
 	}
+	node.list = nil
+	node.prev = nil
+	node.next = nil
+	g.notifyRemoved(node.value)
+	return nil
 }
 
 // insert after prevNode
-func (g *Generic[T]) Insert(v T, a *Node[T]) *Node[T] {
+func (g *Generic[T]) Insert(v T, a *Node[T]) (*Node[T], error) {
 	if a == nil && 0 < g.Size {
-		return nil
+		return nil, errors.New("node is nil")
 	} else if a == nil {
-		return g.Append(v)
+		return g.Append(v), nil
 	}
-	x := &Node[T]{value: v}
+	if err := g.checkNode(a); err != nil {
+		return nil, err
+	}
+	x := &Node[T]{value: v, list: g}
 
 	b := a.next
 
 	// put x between [a,b]; a!=nil
 
 	if b == nil {
-		return g.Append(v)
+		return g.Append(v), nil
 	}
 
 	a.next = x
@@ -232,13 +265,51 @@ func (g *Generic[T]) Insert(v T, a *Node[T]) *Node[T] {
 	x.next = b
 
 	g.Size++
+	g.notifyInserted(x)
 
-	return x
+	return x, nil
+}
+
+// check the links agree with each other, with head and tail, and with Size
+func (g *Generic[T]) Validate() error {
+	if (g.head == nil) != (g.tail == nil) {
+		return errors.New("only one of head and tail is nil")
+	}
+	if g.head != nil && g.head.prev != nil {
+		return errors.New("head has a previous node")
+	}
+	if g.tail != nil && g.tail.next != nil {
+		return errors.New("tail has a next node")
+	}
+	var count uint32 = 0
+	var prev *Node[T]
+	for node := g.head; node != nil; node = node.next {
+		if count == g.Size {
+			return fmt.Errorf("more than %d nodes, or the links form a cycle", g.Size)
+		}
+		if node.list != g {
+			return fmt.Errorf("node %d does not belong to this list", count)
+		}
+		if node.prev != prev {
+			return fmt.Errorf("node %d has a broken prev link", count)
+		}
+		prev = node
+		count++
+	}
+	if prev != g.tail {
+		return errors.New("last node is not the tail")
+	}
+	if count != g.Size {
+		return fmt.Errorf("size is %d but there are %d nodes", g.Size, count)
+	}
+	return nil
 }
 
 type Node[T any] struct {
-	next  *Node[T]
-	prev  *Node[T]
+	next *Node[T]
+	prev *Node[T]
+	// list is the owner, or nil once the node has been removed
+	list  *Generic[T]
 	value T
 }
 
@@ -256,7 +327,11 @@ func (n *Node[T]) Value() T {
 }
 
 func (n *Node[T]) ChangeValue(v T) {
+	old := n.value
 	n.value = v
+	if n.list != nil {
+		n.list.notifyUpdated(n, old, v)
+	}
 }
 
 // Create a radix index to do quick inserts and look-ups.
@@ -113,3 +113,81 @@ func TestMultiSort(t *testing.T) {
 
 	}
 }
+
+func TestNodeOwnership(t *testing.T) {
+	q := ll.CreateGeneric[int]()
+	other := ll.CreateGeneric[int]()
+	q.AppendArray([]int{1, 2, 3})
+	foreign := other.Append(9)
+	node := q.HeadNode().Next()
+
+	assert.NotNil(t, q.Remove(foreign), "removed a node from another list")
+	_, err := q.Insert(4, foreign)
+	assert.NotNil(t, err, "inserted after a node from another list")
+	assert.NotNil(t, q.MoveToFront(foreign))
+	assert.Equal(t, uint32(1), other.Size)
+
+	assert.Nil(t, q.Remove(node))
+	assert.NotNil(t, q.Remove(node), "removed a node twice")
+	assert.Equal(t, uint32(2), q.Size)
+	_, err = q.SplitAt(node)
+	assert.NotNil(t, err, "split at a removed node")
+	assert.Nil(t, q.Validate())
+	assert.Equal(t, true, isEqual([]int{1, 3}, q.Array()))
+
+	// nodes follow their list when lists are joined
+	q.PushListBack(other)
+	assert.Nil(t, q.Remove(foreign))
+	assert.Nil(t, q.Validate())
+	assert.Nil(t, other.Validate())
+
+	p := ll.CreateGeneric[int]()
+	p.Prepend(5)
+	assert.Nil(t, p.Validate(), "prepend to an empty list")
+	tail, ok := p.Tail()
+	assert.True(t, ok)
+	assert.Equal(t, 5, tail)
+}
+
+func TestAllRemove(t *testing.T) {
+	q := ll.CreateGeneric[int]()
+	q.AppendArray([]int{1, 2, 3, 4, 5})
+	nodes := make([]*ll.Node[int], 0)
+	for node := q.HeadNode(); node != nil; node = node.Next() {
+		nodes = append(nodes, node)
+	}
+
+	// remove the node after the current one
+	got := make([]int, 0)
+	for _, v := range q.All() {
+		got = append(got, v)
+		if v == 2 {
+			assert.Nil(t, q.Remove(nodes[2]))
+		}
+	}
+	assert.Equal(t, []int{1, 2, 4, 5}, got)
+
+	// remove the current node and the one after it
+	got = got[:0]
+	for _, v := range q.All() {
+		got = append(got, v)
+		if v == 2 {
+			assert.Nil(t, q.Remove(nodes[1]))
+			assert.Nil(t, q.Remove(nodes[3]))
+		}
+	}
+	assert.Equal(t, []int{1, 2, 5}, got)
+	assert.Equal(t, []int{1, 5}, q.Array())
+
+	// backward, removing the node before the current one
+	q.Append(6)
+	got = got[:0]
+	for _, v := range q.Backward() {
+		got = append(got, v)
+		if v == 6 {
+			assert.Nil(t, q.Remove(nodes[4]))
+		}
+	}
+	assert.Equal(t, []int{6, 1}, got)
+	assert.Nil(t, q.Validate())
+}
@@ -0,0 +1,90 @@
+package list
+
+import "sync/atomic"
+
+type lockFreeNode[T any] struct {
+	value T
+	next  atomic.Pointer[lockFreeNode[T]]
+}
+
+// LockFreeQueue is an unbounded FIFO queue for any number of producers and
+// consumers, after the Michael–Scott queue. Head always points at a dummy
+// node whose successor holds the first element; producers swing the tail
+// and consumers swing the head with a CAS each, so neither side takes a
+// lock and producers do not contend with consumers. The garbage collector
+// keeps a node alive while any goroutine still holds it, which rules out
+// the ABA problem that the original algorithm guards against with counters.
+//
+// The most recently dequeued value stays referenced by the dummy node until
+// the next Dequeue.
+type LockFreeQueue[T any] struct {
+	head atomic.Pointer[lockFreeNode[T]]
+	tail atomic.Pointer[lockFreeNode[T]]
+	size atomic.Int64
+}
+
+func CreateLockFreeQueue[T any]() *LockFreeQueue[T] {
+	q := new(LockFreeQueue[T])
+	dummy := new(lockFreeNode[T])
+	q.head.Store(dummy)
+	q.tail.Store(dummy)
+	return q
+}
+
+// returns the approximate number of elements in the queue
+func (q *LockFreeQueue[T]) Len() uint32 {
+	n := q.size.Load()
+	if n < 0 {
+		return 0
+	}
+	return uint32(n)
+}
+
+// attach v to the end of the queue
+func (q *LockFreeQueue[T]) Enqueue(v T) {
+	node := &lockFreeNode[T]{value: v}
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+		if tail != q.tail.Load() {
+			continue
+		}
+		if next != nil {
+			// another producer linked a node but has not moved the tail yet
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, node) {
+			q.tail.CompareAndSwap(tail, node)
+			q.size.Add(1)
+			return
+		}
+	}
+}
+
+// remove and return the first element of the queue
+func (q *LockFreeQueue[T]) Dequeue() (ans T, is_present bool) {
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+		if head != q.head.Load() {
+			continue
+		}
+		if next == nil {
+			return
+		}
+		if head == tail {
+			// the tail is lagging behind; help it along before moving the head past it
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		// read the value before the CAS; afterwards next may already be
+		// dequeued by someone else
+		ans = next.value
+		if q.head.CompareAndSwap(head, next) {
+			q.size.Add(-1)
+			return ans, true
+		}
+	}
+}
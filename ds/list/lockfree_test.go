@@ -0,0 +1,143 @@
+package list_test
+
+import (
+	"sync"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockFreeQueue(t *testing.T) {
+	q := ll.CreateLockFreeQueue[int]()
+	_, ok := q.Dequeue()
+	assert.False(t, ok)
+	for i := 0; i < 5; i++ {
+		q.Enqueue(i)
+	}
+	assert.Equal(t, uint32(5), q.Len())
+	for i := 0; i < 5; i++ {
+		v, ok := q.Dequeue()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok = q.Dequeue()
+	assert.False(t, ok)
+	assert.Equal(t, uint32(0), q.Len())
+}
+
+type tagged struct {
+	producer int
+	seq      int
+}
+
+// run with go test -race.
+// Every enqueued element must come out exactly once, and since a FIFO
+// queue is linearizable, each consumer must see every producer's elements
+// in the order that producer enqueued them.
+func TestLockFreeQueueStress(t *testing.T) {
+	q := ll.CreateLockFreeQueue[tagged]()
+	producers := 4
+	consumers := 4
+	N := 2000
+
+	wg := &sync.WaitGroup{}
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				q.Enqueue(tagged{producer: p, seq: i})
+			}
+		}(p)
+	}
+
+	received := make([][]tagged, consumers)
+	doneC := make(chan struct{})
+	cwg := &sync.WaitGroup{}
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				v, ok := q.Dequeue()
+				if ok {
+					received[c] = append(received[c], v)
+					continue
+				}
+				select {
+				case <-doneC:
+					// producers have finished; drain whatever is left
+					for v, ok = q.Dequeue(); ok; v, ok = q.Dequeue() {
+						received[c] = append(received[c], v)
+					}
+					return
+				default:
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	close(doneC)
+	cwg.Wait()
+
+	seen := make([][]bool, producers)
+	for p := range seen {
+		seen[p] = make([]bool, N)
+	}
+	total := 0
+	for c := 0; c < consumers; c++ {
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+		for _, v := range received[c] {
+			assert.False(t, seen[v.producer][v.seq], "element dequeued twice")
+			seen[v.producer][v.seq] = true
+			assert.Less(t, last[v.producer], v.seq, "producer order violated")
+			last[v.producer] = v.seq
+			total++
+		}
+	}
+	assert.Equal(t, producers*N, total)
+	assert.Equal(t, uint32(0), q.Len())
+}
+
+func BenchmarkLockFreeQueueParallel(b *testing.B) {
+	q := ll.CreateLockFreeQueue[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Enqueue(1)
+			q.Dequeue()
+		}
+	})
+}
+
+func BenchmarkSyncListParallel(b *testing.B) {
+	q := ll.CreateSync[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Append(1)
+			q.Pop()
+		}
+	})
+}
+
+// many producers and no consumers, as on the ingestion path
+func BenchmarkLockFreeQueueEnqueue(b *testing.B) {
+	q := ll.CreateLockFreeQueue[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Enqueue(1)
+		}
+	})
+}
+
+func BenchmarkSyncListAppend(b *testing.B) {
+	q := ll.CreateSync[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Append(1)
+		}
+	})
+}
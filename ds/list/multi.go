@@ -0,0 +1,362 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// an element of an ordered index. key is taken from the element when the
+// node is indexed, so the skip list stays sorted even if the element is a
+// pointer whose fields change before the index hears about it; seq breaks
+// ties between equal keys so that every node has exactly one place in the
+// skip list.
+type orderedEntry[T any, K any] struct {
+	key  K
+	seq  uint64
+	node *Node[T]
+}
+
+// the part of an ordered index that does not depend on the key type
+type orderedIndex[T any] interface {
+	add(node *Node[T], seq uint64)
+	remove(node *Node[T])
+	// returns true if the index is unique and a node other than self has
+	// the key of v
+	conflicts(v T, self *Node[T]) bool
+	ascend() iter.Seq[*Node[T]]
+	descend() iter.Seq[*Node[T]]
+}
+
+type keyedOrderedIndex[T any, K any] struct {
+	getKey  func(T) K
+	compare CompareCallback[K]
+	unique  bool
+	s       *Sorted[orderedEntry[T, K]]
+	// the entry each node is filed under
+	entries map[*Node[T]]orderedEntry[T, K]
+}
+
+func (o *keyedOrderedIndex[T, K]) entryCompare(a orderedEntry[T, K], b orderedEntry[T, K]) int {
+	if c := o.compare(a.key, b.key); c != 0 {
+		return c
+	}
+	if a.seq < b.seq {
+		return -1
+	} else if a.seq > b.seq {
+		return 1
+	}
+	return 0
+}
+
+// a probe sorts before every entry with a key equal to key
+func probe[T any, K any](key K) orderedEntry[T, K] {
+	return orderedEntry[T, K]{key: key, seq: 0}
+}
+
+func (o *keyedOrderedIndex[T, K]) add(node *Node[T], seq uint64) {
+	e := orderedEntry[T, K]{key: o.getKey(node.value), seq: seq, node: node}
+	o.entries[node] = e
+	o.s.Insert(e)
+}
+
+func (o *keyedOrderedIndex[T, K]) remove(node *Node[T]) {
+	e, present := o.entries[node]
+	if !present {
+		return
+	}
+	delete(o.entries, node)
+	o.s.Delete(e)
+}
+
+func (o *keyedOrderedIndex[T, K]) conflicts(v T, self *Node[T]) bool {
+	if !o.unique {
+		return false
+	}
+	key := o.getKey(v)
+	for e := range o.s.ValuesFrom(probe[T](key)) {
+		if o.compare(e.key, key) != 0 {
+			return false
+		}
+		if e.node != self {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *keyedOrderedIndex[T, K]) ascend() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		for e := range o.s.Values() {
+			if !yield(e.node) {
+				return
+			}
+		}
+	}
+}
+
+func (o *keyedOrderedIndex[T, K]) descend() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		for e := range o.s.Backward() {
+			if !yield(e.node) {
+				return
+			}
+		}
+	}
+}
+
+// MultiIndex is a container in the spirit of Boost.MultiIndex. Elements are
+// kept in insertion order in a Generic list and, at the same time, in any
+// number of named indexes: hash and radix indexes keyed by a string (see
+// Indexed), and ordered indexes sorted by a key taken from each element
+// (see AddOrderedIndex). Every index is updated on Insert, Modify and
+// Erase; a change that would break a unique index is rejected. Calling
+// ChangeValue on a node also updates every index, but skips the unique
+// checks. Elements may be pointers, but their fields must then only be
+// changed inside Modify.
+type MultiIndex[T any] struct {
+	idx     *Indexed[T]
+	ordered map[string]orderedIndex[T]
+	// insertion sequence of each node, used to order equal keys
+	seq     map[*Node[T]]uint64
+	nextSeq uint64
+}
+
+func CreateMultiIndex[T any]() *MultiIndex[T] {
+	m := new(MultiIndex[T])
+	m.idx = CreateIndexed[T]()
+	m.ordered = make(map[string]orderedIndex[T])
+	m.seq = make(map[*Node[T]]uint64)
+	// seq 0 is reserved for probes
+	m.nextSeq = 1
+	m.idx.g.AddObserver(orderedUpdater[T]{m})
+	return m
+}
+
+// moves nodes within the ordered indexes when Node.ChangeValue is called
+type orderedUpdater[T any] struct {
+	m *MultiIndex[T]
+}
+
+func (u orderedUpdater[T]) Inserted(node *Node[T]) {}
+
+func (u orderedUpdater[T]) Removed(value T) {}
+
+func (u orderedUpdater[T]) Updated(node *Node[T], oldValue T, newValue T) {
+	if _, present := u.m.seq[node]; !present {
+		return
+	}
+	u.m.removeFromOrdered(node)
+	u.m.addToOrdered(node)
+}
+
+func (m *MultiIndex[T]) hasIndex(name string) bool {
+	_, isOrdered := m.ordered[name]
+	_, isKeyed := m.idx.indexes[name]
+	return isOrdered || isKeyed
+}
+
+// register a hash or radix index; see Indexed.AddIndex
+func (m *MultiIndex[T]) AddIndex(name string, kind IndexKind, unique bool, getKey func(T) string) error {
+	if m.hasIndex(name) {
+		return fmt.Errorf("index %s already exists", name)
+	}
+	return m.idx.AddIndex(name, kind, unique, getKey)
+}
+
+// register an ordered index on m that keeps the elements sorted by the key
+// getKey returns for them. The key is taken when an element is inserted or
+// modified, so it must not share memory with the element. Equal keys are
+// kept in insertion order. If unique is set, no two elements may have
+// equal keys.
+func AddOrderedIndex[T any, K any](m *MultiIndex[T], name string, unique bool, getKey func(T) K, compare CompareCallback[K]) error {
+	if m.hasIndex(name) {
+		return fmt.Errorf("index %s already exists", name)
+	}
+	o := &keyedOrderedIndex[T, K]{getKey: getKey, compare: compare, unique: unique}
+	o.s = CreateSorted(o.entryCompare)
+	o.entries = make(map[*Node[T]]orderedEntry[T, K])
+	for node := m.idx.HeadNode(); node != nil; node = node.Next() {
+		if o.conflicts(node.Value(), nil) {
+			return fmt.Errorf("duplicate key in unique index %s", name)
+		}
+		o.add(node, m.seq[node])
+	}
+	m.ordered[name] = o
+	return nil
+}
+
+// self may keep its own key
+func (m *MultiIndex[T]) checkOrderedUnique(v T, self *Node[T]) error {
+	for name, o := range m.ordered {
+		if o.conflicts(v, self) {
+			return fmt.Errorf("duplicate key in unique index %s", name)
+		}
+	}
+	return nil
+}
+
+func (m *MultiIndex[T]) addToOrdered(node *Node[T]) {
+	seq := m.seq[node]
+	for _, o := range m.ordered {
+		o.add(node, seq)
+	}
+}
+
+// removes node from the keys it was filed under, whatever its value is now
+func (m *MultiIndex[T]) removeFromOrdered(node *Node[T]) {
+	for _, o := range m.ordered {
+		o.remove(node)
+	}
+}
+
+// returns the number of elements
+func (m *MultiIndex[T]) Len() uint32 {
+	return m.idx.Len()
+}
+
+// add v at the end of the insertion order and to every index
+func (m *MultiIndex[T]) Insert(v T) (*Node[T], error) {
+	if err := m.checkOrderedUnique(v, nil); err != nil {
+		return nil, err
+	}
+	node, err := m.idx.Append(v)
+	if err != nil {
+		return nil, err
+	}
+	m.seq[node] = m.nextSeq
+	m.nextSeq++
+	m.addToOrdered(node)
+	return node, nil
+}
+
+// change the element held by node and move it to its new keys. modify gets
+// a copy of the value; if the result breaks a unique index, the change is
+// dropped and an error is returned. If T is a pointer, changes made through
+// it cannot be dropped: point *v at a modified copy to keep the element
+// intact when the change is rejected.
+func (m *MultiIndex[T]) Modify(node *Node[T], modify func(v *T)) error {
+	if err := m.idx.g.checkNode(node); err != nil {
+		return err
+	}
+	v := node.Value()
+	modify(&v)
+
+	if err := m.checkOrderedUnique(v, node); err != nil {
+		return err
+	}
+	// orderedUpdater moves the node within the ordered indexes
+	return m.idx.ChangeValue(node, v)
+}
+
+// remove node from the container and every index
+func (m *MultiIndex[T]) Erase(node *Node[T]) error {
+	if err := m.idx.g.checkNode(node); err != nil {
+		return err
+	}
+	m.removeFromOrdered(node)
+	delete(m.seq, node)
+	return m.idx.Remove(node)
+}
+
+// returns the first node with the key in the named hash or radix index, or nil
+func (m *MultiIndex[T]) Find(name string, key string) (*Node[T], error) {
+	return m.idx.GetByKey(name, key)
+}
+
+// All yields every node in insertion order. The loop body may Insert,
+// Modify and Erase nodes; see Generic.All.
+func (m *MultiIndex[T]) All() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		g := m.idx.g
+		for node := g.head; node != nil; {
+			prev, next := node.prev, node.next
+			if !yield(node) {
+				return
+			}
+			node = g.after(node, prev, next)
+		}
+	}
+}
+
+// Equal yields the nodes with the key in the named hash or radix index,
+// in insertion order
+func (m *MultiIndex[T]) Equal(name string, key string) (iter.Seq[*Node[T]], error) {
+	list, err := m.idx.GetAllByKey(name, key)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(*Node[T]) bool) {
+		for _, node := range list {
+			if !yield(node) {
+				return
+			}
+		}
+	}, nil
+}
+
+// Prefix yields the key and node of every element whose key in the named
+// radix index starts with prefix, in key order
+func (m *MultiIndex[T]) Prefix(name string, prefix string) (iter.Seq2[string, *Node[T]], error) {
+	spec, present := m.idx.indexes[name]
+	if !present {
+		return nil, fmt.Errorf("no index %s", name)
+	}
+	if spec.kind != INDEX_RADIX {
+		return nil, fmt.Errorf("index %s is not a radix index", name)
+	}
+	return func(yield func(string, *Node[T]) bool) {
+		m.idx.WalkPrefix(name, prefix, func(key string, node *Node[T]) bool {
+			return !yield(key, node)
+		})
+	}, nil
+}
+
+func (m *MultiIndex[T]) orderedIndex(name string) (orderedIndex[T], error) {
+	o, present := m.ordered[name]
+	if !present {
+		return nil, fmt.Errorf("no ordered index %s", name)
+	}
+	return o, nil
+}
+
+// Ascend yields the nodes sorted by the named ordered index
+func (m *MultiIndex[T]) Ascend(name string) (iter.Seq[*Node[T]], error) {
+	o, err := m.orderedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	return o.ascend(), nil
+}
+
+// Descend yields the nodes in reverse order of the named ordered index
+func (m *MultiIndex[T]) Descend(name string) (iter.Seq[*Node[T]], error) {
+	o, err := m.orderedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	return o.descend(), nil
+}
+
+// AscendRange yields the nodes of m with keys in [from, to) in the order of
+// the named ordered index, whose key type must be K
+func AscendRange[T any, K any](m *MultiIndex[T], name string, from K, to K) (iter.Seq[*Node[T]], error) {
+	x, err := m.orderedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := x.(*keyedOrderedIndex[T, K])
+	if !ok {
+		return nil, fmt.Errorf("index %s is not keyed by %T", name, from)
+	}
+	if 0 < o.compare(from, to) {
+		return nil, errors.New("from is greater than to")
+	}
+	return func(yield func(*Node[T]) bool) {
+		for e := range o.s.ValuesFrom(probe[T](from)) {
+			if 0 <= o.compare(e.key, to) || !yield(e.node) {
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,236 @@
+package list_test
+
+import (
+	"testing"
+
+	sgo "github.com/SolmateDev/solana-go"
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+type bidRecord struct {
+	id    string
+	owner sgo.PublicKey
+	bid   uint64
+}
+
+func bidOf(p bidRecord) uint64 {
+	return p.bid
+}
+
+func compareBid(a uint64, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func ids(nodes func(func(*ll.Node[bidRecord]) bool)) []string {
+	ans := make([]string, 0)
+	for node := range nodes {
+		ans = append(ans, node.Value().id)
+	}
+	return ans
+}
+
+func createBidRecords(t *testing.T) *ll.MultiIndex[bidRecord] {
+	m := ll.CreateMultiIndex[bidRecord]()
+	assert.Nil(t, m.AddIndex("id", ll.INDEX_HASH, true, func(p bidRecord) string { return p.id }))
+	assert.Nil(t, m.AddIndex("owner", ll.INDEX_HASH, false, func(p bidRecord) string { return string(p.owner[:]) }))
+	assert.Nil(t, m.AddIndex("name", ll.INDEX_RADIX, true, func(p bidRecord) string { return p.id }))
+	assert.Nil(t, ll.AddOrderedIndex(m, "bid", false, bidOf, compareBid))
+	assert.NotNil(t, ll.AddOrderedIndex(m, "id", false, bidOf, compareBid), "added an index twice")
+	return m
+}
+
+func TestMultiIndex(t *testing.T) {
+	m := createBidRecords(t)
+	alice := sgo.PublicKey{1}
+	bob := sgo.PublicKey{2}
+	for _, p := range []bidRecord{
+		{id: "us-east", owner: alice, bid: 30},
+		{id: "us-west", owner: bob, bid: 10},
+		{id: "eu-central", owner: alice, bid: 20},
+		{id: "ap-south", owner: bob, bid: 20},
+	} {
+		_, err := m.Insert(p)
+		assert.Nil(t, err)
+	}
+	_, err := m.Insert(bidRecord{id: "us-east", bid: 1})
+	assert.NotNil(t, err, "inserted a duplicate id")
+	assert.Equal(t, uint32(4), m.Len())
+
+	assert.Equal(t, []string{"us-east", "us-west", "eu-central", "ap-south"}, ids(m.All()))
+	asc, err := m.Ascend("bid")
+	assert.Nil(t, err)
+	// equal bids stay in insertion order
+	assert.Equal(t, []string{"us-west", "eu-central", "ap-south", "us-east"}, ids(asc))
+	desc, err := m.Descend("bid")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"us-east", "ap-south", "eu-central", "us-west"}, ids(desc))
+	r, err := ll.AscendRange(m, "bid", uint64(15), uint64(30))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"eu-central", "ap-south"}, ids(r))
+	_, err = ll.AscendRange(m, "bid", 15, 30)
+	assert.NotNil(t, err, "walked an index with the wrong key type")
+	byOwner, err := m.Equal("owner", string(alice[:]))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"us-east", "eu-central"}, ids(byOwner))
+	prefix, err := m.Prefix("name", "us-")
+	assert.Nil(t, err)
+	keys := make([]string, 0)
+	for k := range prefix {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []string{"us-east", "us-west"}, keys)
+	_, err = m.Prefix("id", "us")
+	assert.NotNil(t, err, "walked a prefix of a hash index")
+	_, err = m.Ascend("owner")
+	assert.NotNil(t, err, "walked a hash index in order")
+
+	// Modify moves the node in every index
+	node, err := m.Find("id", "us-west")
+	assert.Nil(t, err)
+	assert.Nil(t, m.Modify(node, func(p *bidRecord) {
+		p.bid = 50
+		p.owner = alice
+	}))
+	asc, _ = m.Ascend("bid")
+	assert.Equal(t, []string{"eu-central", "ap-south", "us-east", "us-west"}, ids(asc))
+	byOwner, _ = m.Equal("owner", string(alice[:]))
+	assert.Equal(t, []string{"us-east", "eu-central", "us-west"}, ids(byOwner))
+
+	// a Modify that breaks a unique index changes nothing
+	assert.NotNil(t, m.Modify(node, func(p *bidRecord) {
+		p.id = "us-east"
+		p.bid = 0
+	}))
+	assert.Equal(t, "us-west", node.Value().id)
+	asc, _ = m.Ascend("bid")
+	assert.Equal(t, []string{"eu-central", "ap-south", "us-east", "us-west"}, ids(asc))
+
+	// erase while iterating
+	for n := range m.All() {
+		if n.Value().owner == alice {
+			assert.Nil(t, m.Erase(n))
+		}
+	}
+	assert.Equal(t, []string{"ap-south"}, ids(m.All()))
+	asc, _ = m.Ascend("bid")
+	assert.Equal(t, []string{"ap-south"}, ids(asc))
+	assert.NotNil(t, m.Erase(node), "erased a node twice")
+	node, err = m.Find("id", "us-west")
+	assert.Nil(t, err)
+	assert.Nil(t, node)
+}
+
+func TestMultiIndexUniqueOrdered(t *testing.T) {
+	m := ll.CreateMultiIndex[bidRecord]()
+	a, err := m.Insert(bidRecord{id: "a", bid: 1})
+	assert.Nil(t, err)
+	_, err = m.Insert(bidRecord{id: "b", bid: 1})
+	assert.Nil(t, err)
+	assert.NotNil(t, ll.AddOrderedIndex(m, "bid", true, bidOf, compareBid), "built a unique index over duplicates")
+
+	assert.Nil(t, m.Modify(a, func(p *bidRecord) { p.bid = 2 }))
+	assert.Nil(t, ll.AddOrderedIndex(m, "bid", true, bidOf, compareBid))
+	_, err = m.Insert(bidRecord{id: "c", bid: 2})
+	assert.NotNil(t, err, "inserted a duplicate bid")
+	// a node may keep its own value under a unique index
+	assert.Nil(t, m.Modify(a, func(p *bidRecord) { p.id = "a2" }))
+	b, _ := m.Find("id", "b")
+	assert.Nil(t, b, "found a key in an index that does not exist")
+	asc, _ := m.Ascend("bid")
+	assert.Equal(t, []string{"b", "a2"}, ids(asc))
+}
+
+func TestMultiIndexNodeChangeValue(t *testing.T) {
+	m := createBidRecords(t)
+	nodes := make([]*ll.Node[bidRecord], 0)
+	for i, id := range []string{"a", "b", "c"} {
+		node, err := m.Insert(bidRecord{id: id, bid: uint64(10 * (i + 1))})
+		assert.Nil(t, err)
+		nodes = append(nodes, node)
+	}
+
+	// bypass the container and change the nodes directly
+	nodes[0].ChangeValue(bidRecord{id: "x", bid: 40})
+	asc, err := m.Ascend("bid")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b", "c", "x"}, ids(asc))
+	found, err := m.Find("id", "x")
+	assert.Nil(t, err)
+	assert.Equal(t, nodes[0], found)
+
+	assert.Nil(t, m.Erase(nodes[0]))
+	assert.Nil(t, m.Erase(nodes[1]))
+	asc, err = m.Ascend("bid")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c"}, ids(asc))
+	_, err = m.Insert(bidRecord{id: "a", bid: 5})
+	assert.Nil(t, err, "stale key blocked a unique insert")
+	_, err = m.Insert(bidRecord{id: "x", bid: 50})
+	assert.Nil(t, err, "stale key blocked a unique insert")
+	asc, err = m.Ascend("bid")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "c", "x"}, ids(asc))
+}
+
+type pricedPipeline struct {
+	id    string
+	price uint64
+}
+
+func TestMultiIndexPointer(t *testing.T) {
+	m := ll.CreateMultiIndex[*pricedPipeline]()
+	assert.Nil(t, m.AddIndex("id", ll.INDEX_HASH, true, func(p *pricedPipeline) string { return p.id }))
+	assert.Nil(t, ll.AddOrderedIndex(m, "price", false, func(p *pricedPipeline) uint64 { return p.price }, compareBid))
+	prices := func() []uint64 {
+		ans := make([]uint64, 0)
+		asc, err := m.Ascend("price")
+		assert.Nil(t, err)
+		for node := range asc {
+			ans = append(ans, node.Value().price)
+		}
+		return ans
+	}
+
+	var nine *ll.Node[*pricedPipeline]
+	for i, price := range []uint64{5, 1, 9, 3} {
+		node, err := m.Insert(&pricedPipeline{id: string(rune('a' + i)), price: price})
+		assert.Nil(t, err)
+		if price == 9 {
+			nine = node
+		}
+	}
+	assert.Equal(t, []uint64{1, 3, 5, 9}, prices())
+
+	// change the record in place through the pointer
+	assert.Nil(t, m.Modify(nine, func(p **pricedPipeline) { (*p).price = 0 }))
+	assert.Equal(t, []uint64{0, 1, 3, 5}, prices())
+	assert.Nil(t, m.Modify(nine, func(p **pricedPipeline) { (*p).price = 4 }))
+	assert.Equal(t, []uint64{1, 3, 4, 5}, prices())
+
+	// a rejected change to a copy leaves the record alone
+	assert.NotNil(t, m.Modify(nine, func(p **pricedPipeline) {
+		c := **p
+		c.id = "a"
+		c.price = 100
+		*p = &c
+	}))
+	assert.Equal(t, uint64(4), nine.Value().price)
+	assert.Equal(t, []uint64{1, 3, 4, 5}, prices())
+
+	assert.Nil(t, m.Erase(nine))
+	assert.Equal(t, uint32(3), m.Len())
+	assert.Equal(t, []uint64{1, 3, 5}, prices())
+	r, err := ll.AscendRange(m, "price", uint64(2), uint64(6))
+	assert.Nil(t, err)
+	count := 0
+	for range r {
+		count++
+	}
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,221 @@
+package list
+
+import (
+	"context"
+
+	"github.com/solpipe/solpipe-util/ds/sub"
+)
+
+// Observer receives the changes made to a Generic list. The methods run
+// synchronously right after the change, on the goroutine that made it.
+// Relinking nodes within the list (Move*, Sort, Reverse) is not reported.
+type Observer[T any] interface {
+	// node has been added to the list
+	Inserted(node *Node[T])
+	// an element has left the list
+	Removed(value T)
+	// ChangeValue was called on a node in the list
+	Updated(node *Node[T], oldValue T, newValue T)
+}
+
+// SyncObserver receives the changes made to a SyncList. The methods run
+// while the list lock is held, so events arrive in the order the changes
+// were made, one at a time. They must not call methods of the list or its
+// nodes other than SyncNode.Value.
+type SyncObserver[T any] interface {
+	Inserted(node *SyncNode[T])
+	Removed(value T)
+	Updated(node *SyncNode[T], oldValue T, newValue T)
+}
+
+type observerEntry[O any] struct {
+	o O
+}
+
+// remove entry from list without reordering the others
+func removeObserver[O any](list []*observerEntry[O], entry *observerEntry[O]) []*observerEntry[O] {
+	for i, x := range list {
+		if x == entry {
+			// copy so that a notification in progress keeps its slice intact
+			ans := make([]*observerEntry[O], 0, len(list)-1)
+			ans = append(ans, list[:i]...)
+			return append(ans, list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// register o and return a function that unregisters it
+func (g *Generic[T]) AddObserver(o Observer[T]) (remove func()) {
+	entry := &observerEntry[Observer[T]]{o: o}
+	g.observers = append(g.observers, entry)
+	return func() { g.observers = removeObserver(g.observers, entry) }
+}
+
+func (g *Generic[T]) notifyInserted(node *Node[T]) {
+	for _, x := range g.observers {
+		x.o.Inserted(node)
+	}
+}
+
+func (g *Generic[T]) notifyRemoved(value T) {
+	for _, x := range g.observers {
+		x.o.Removed(value)
+	}
+}
+
+func (g *Generic[T]) notifyUpdated(node *Node[T], oldValue T, newValue T) {
+	for _, x := range g.observers {
+		x.o.Updated(node, oldValue, newValue)
+	}
+}
+
+// register o and return a function that unregisters it
+func (g *SyncList[T]) AddObserver(o SyncObserver[T]) (remove func()) {
+	entry := &observerEntry[SyncObserver[T]]{o: o}
+	g.mutex.Lock()
+	g.observers = append(g.observers, entry)
+	g.mutex.Unlock()
+	return func() {
+		g.mutex.Lock()
+		g.observers = removeObserver(g.observers, entry)
+		g.mutex.Unlock()
+	}
+}
+
+// the mutex must be held
+func (g *SyncList[T]) notifyInserted(node *SyncNode[T]) {
+	for _, x := range g.observers {
+		x.o.Inserted(node)
+	}
+}
+
+// the mutex must be held
+func (g *SyncList[T]) notifyRemoved(value T) {
+	for _, x := range g.observers {
+		x.o.Removed(value)
+	}
+}
+
+type EventKind uint8
+
+const (
+	EVENT_INSERTED EventKind = 0
+	EVENT_REMOVED  EventKind = 1
+	EVENT_UPDATED  EventKind = 2
+)
+
+// Event is a list change as published to subscribers. Value is the
+// inserted, removed or new value; Old is only set for EVENT_UPDATED.
+type Event[T any] struct {
+	Kind  EventKind
+	Value T
+	Old   T
+}
+
+// number of events that can be queued up before a list change has to wait
+// for the publishing goroutine
+const EVENT_BUFFER_SIZE = 100
+
+// forwards events to the goroutine running the SubHome
+type eventForwarder[T any] struct {
+	ctx    context.Context
+	eventC chan<- Event[T]
+}
+
+func (f eventForwarder[T]) send(e Event[T]) {
+	// once the context is done, events are dropped
+	select {
+	case <-f.ctx.Done():
+	case f.eventC <- e:
+	}
+}
+
+type genericForwarder[T any] struct {
+	eventForwarder[T]
+	remove func()
+}
+
+// Generic is not safe for concurrent use, so the forwarder cannot be
+// removed by the publishing goroutine; it removes itself on the first
+// change after ctx is done instead
+func (f *genericForwarder[T]) send(e Event[T]) {
+	if f.ctx.Err() != nil {
+		f.remove()
+		return
+	}
+	f.eventForwarder.send(e)
+}
+
+func (f *genericForwarder[T]) Inserted(node *Node[T]) {
+	f.send(Event[T]{Kind: EVENT_INSERTED, Value: node.Value()})
+}
+
+func (f *genericForwarder[T]) Removed(value T) {
+	f.send(Event[T]{Kind: EVENT_REMOVED, Value: value})
+}
+
+func (f *genericForwarder[T]) Updated(node *Node[T], oldValue T, newValue T) {
+	f.send(Event[T]{Kind: EVENT_UPDATED, Value: newValue, Old: oldValue})
+}
+
+type syncForwarder[T any] struct {
+	eventForwarder[T]
+}
+
+func (f syncForwarder[T]) Inserted(node *SyncNode[T]) {
+	f.send(Event[T]{Kind: EVENT_INSERTED, Value: node.Value()})
+}
+
+func (f syncForwarder[T]) Removed(value T) {
+	f.send(Event[T]{Kind: EVENT_REMOVED, Value: value})
+}
+
+func (f syncForwarder[T]) Updated(node *SyncNode[T], oldValue T, newValue T) {
+	f.send(Event[T]{Kind: EVENT_UPDATED, Value: newValue, Old: oldValue})
+}
+
+// publish the changes to the list through a sub.SubHome until ctx is done.
+// Subscribe with sub.SubscriptionRequest on the returned channel.
+// A list change waits while the event buffer is full. Once ctx is done,
+// the publisher is unregistered by the next change to the list.
+func (g *Generic[T]) Publish(ctx context.Context) chan<- sub.ResponseChannel[Event[T]] {
+	eventC := make(chan Event[T], EVENT_BUFFER_SIZE)
+	f := &genericForwarder[T]{eventForwarder: eventForwarder[T]{ctx: ctx, eventC: eventC}}
+	f.remove = g.AddObserver(f)
+	home := sub.CreateSubHome[Event[T]]()
+	go loopEventHome(ctx, home, eventC)
+	return home.ReqC
+}
+
+// publish the changes to the list through a sub.SubHome until ctx is done.
+// Subscribe with sub.SubscriptionRequest on the returned channel.
+// A list change waits while the event buffer is full. Once ctx is done,
+// the publisher is unregistered.
+func (g *SyncList[T]) Publish(ctx context.Context) chan<- sub.ResponseChannel[Event[T]] {
+	eventC := make(chan Event[T], EVENT_BUFFER_SIZE)
+	remove := g.AddObserver(syncForwarder[T]{eventForwarder[T]{ctx: ctx, eventC: eventC}})
+	home := sub.CreateSubHome[Event[T]]()
+	go func() {
+		loopEventHome(ctx, home, eventC)
+		remove()
+	}()
+	return home.ReqC
+}
+
+func loopEventHome[T any](ctx context.Context, home *sub.SubHome[Event[T]], eventC <-chan Event[T]) {
+	defer home.Close()
+	doneC := ctx.Done()
+	for {
+		select {
+		case <-doneC:
+			return
+		case r := <-home.ReqC:
+			home.Receive(r)
+		case id := <-home.DeleteC:
+			home.Delete(id)
+		case e := <-eventC:
+			home.Broadcast(e)
+		}
+	}
+}
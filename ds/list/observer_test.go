@@ -0,0 +1,233 @@
+package list_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/solpipe/solpipe-util/ds/sub"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) Inserted(node *ll.Node[int]) {
+	r.events = append(r.events, fmt.Sprintf("+%d", node.Value()))
+}
+
+func (r *recorder) Removed(value int) {
+	r.events = append(r.events, fmt.Sprintf("-%d", value))
+}
+
+func (r *recorder) Updated(node *ll.Node[int], oldValue int, newValue int) {
+	r.events = append(r.events, fmt.Sprintf("%d>%d", oldValue, newValue))
+}
+
+func TestGenericObserver(t *testing.T) {
+	q := ll.CreateGeneric[int]()
+	r := &recorder{}
+	remove := q.AddObserver(r)
+
+	a := q.Append(1)
+	q.Prepend(0)
+	b, err := q.Insert(2, a)
+	assert.Nil(t, err)
+	q.InsertSorted(3, scb)
+	b.ChangeValue(20)
+	assert.Nil(t, q.Remove(a))
+	q.Pop()
+	assert.Equal(t, []string{"+1", "+0", "+2", "+3", "2>20", "-1", "-0"}, r.events)
+
+	// moving nodes between lists is reported by both lists
+	other := ll.CreateGeneric[int]()
+	other.Append(7)
+	ro := &recorder{}
+	other.AddObserver(ro)
+	r.events = nil
+	q.PushListBack(other)
+	assert.Equal(t, []string{"+7"}, r.events)
+	assert.Equal(t, []string{"-7"}, ro.events)
+
+	r.events = nil
+	_, err = q.SplitAt(q.HeadNode().Next())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-3", "-7"}, r.events)
+
+	// relinking is not reported, and nothing is sent after remove
+	r.events = nil
+	q.Append(9)
+	q.Reverse()
+	remove()
+	q.Append(10)
+	assert.Equal(t, []string{"+9"}, r.events)
+}
+
+type syncRecorder struct {
+	inserted int
+	removed  int
+	updated  int
+}
+
+func (r *syncRecorder) Inserted(node *ll.SyncNode[int]) {
+	r.inserted++
+}
+
+func (r *syncRecorder) Removed(value int) {
+	r.removed++
+}
+
+func (r *syncRecorder) Updated(node *ll.SyncNode[int], oldValue int, newValue int) {
+	r.updated++
+}
+
+// run with go test -race; the observer has no lock of its own
+func TestSyncListObserver(t *testing.T) {
+	q := ll.CreateSync[int]()
+	r := &syncRecorder{}
+	remove := q.AddObserver(r)
+	workers := 4
+	N := 200
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				node := q.Append(i)
+				node.ChangeValue(-i)
+				if i%2 == 0 {
+					q.Remove(node)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	remove()
+	assert.Equal(t, workers*N, r.inserted)
+	assert.Equal(t, workers*N, r.updated)
+	assert.Equal(t, workers*N/2, r.removed)
+	assert.Equal(t, workers*N-r.removed, int(q.Len()))
+}
+
+func TestPublish(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	q := ll.CreateSync[int]()
+	s := sub.SubscriptionRequest(q.Publish(ctx), func(e ll.Event[int]) bool { return true })
+
+	node := q.Append(1)
+	node.ChangeValue(2)
+	q.Pop()
+	expected := []ll.Event[int]{
+		{Kind: ll.EVENT_INSERTED, Value: 1},
+		{Kind: ll.EVENT_UPDATED, Value: 2, Old: 1},
+		{Kind: ll.EVENT_REMOVED, Value: 2},
+	}
+	for _, e := range expected {
+		select {
+		case <-time.After(5 * time.Second):
+			t.Fatal("time out")
+		case err := <-s.ErrorC:
+			t.Fatal(err)
+		case x := <-s.StreamC:
+			assert.Equal(t, e, x)
+		}
+	}
+
+	// once the context is done, the list no longer waits on the publisher
+	cancel()
+	for i := 0; i < 2*ll.EVENT_BUFFER_SIZE; i++ {
+		q.Append(i)
+	}
+}
+
+// counts how often the publisher looks at the context
+type countingContext struct {
+	context.Context
+	calls atomic.Int64
+}
+
+func (c *countingContext) Done() <-chan struct{} {
+	c.calls.Add(1)
+	return c.Context.Done()
+}
+
+func (c *countingContext) Err() error {
+	c.calls.Add(1)
+	return c.Context.Err()
+}
+
+func TestPublishUnregister(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := &countingContext{Context: parent}
+	g := ll.CreateGeneric[int]()
+	g.Publish(ctx)
+	cancel()
+	// the first change after the cancel removes the publisher
+	g.Append(1)
+	n := ctx.calls.Load()
+	g.Append(2)
+	g.HeadNode().ChangeValue(3)
+	g.Pop()
+	assert.Equal(t, n, ctx.calls.Load(), "publisher still registered")
+
+	parent, cancel = context.WithCancel(context.Background())
+	ctx = &countingContext{Context: parent}
+	q := ll.CreateSync[int]()
+	q.Publish(ctx)
+	cancel()
+	assert.Eventually(t, func() bool {
+		n := ctx.calls.Load()
+		q.Append(1)
+		return n == ctx.calls.Load()
+	}, 5*time.Second, time.Millisecond, "publisher still registered")
+}
+
+// records the state of the list each time it is notified
+type stateRecorder struct {
+	g      *ll.Generic[int]
+	states []string
+}
+
+func (r *stateRecorder) record() {
+	r.states = append(r.states, fmt.Sprintf("%v/%d", r.g.Array(), r.g.Size))
+}
+
+func (r *stateRecorder) Inserted(node *ll.Node[int]) {
+	r.record()
+}
+
+func (r *stateRecorder) Removed(value int) {
+	r.record()
+}
+
+func (r *stateRecorder) Updated(node *ll.Node[int], oldValue int, newValue int) {
+	r.record()
+}
+
+func TestObserverRelinkState(t *testing.T) {
+	q := ll.CreateGeneric[int]()
+	q.AppendArray([]int{1, 2})
+	other := ll.CreateGeneric[int]()
+	other.AppendArray([]int{3, 4})
+	rq := &stateRecorder{g: q}
+	q.AddObserver(rq)
+	ro := &stateRecorder{g: other}
+	other.AddObserver(ro)
+
+	q.PushListBack(other)
+	assert.Equal(t, []string{"[1 2 3 4]/4", "[1 2 3 4]/4"}, rq.states)
+	assert.Equal(t, []string{"[]/0", "[]/0"}, ro.states)
+
+	rq.states = nil
+	_, err := q.SplitAt(q.TailNode().Prev())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"[1 2]/2", "[1 2]/2"}, rq.states)
+	assert.Nil(t, q.Validate())
+}
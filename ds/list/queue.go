@@ -0,0 +1,162 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/solpipe/solpipe-util/ds/internal/signal"
+)
+
+var ErrQueueClosed = errors.New("queue is closed")
+
+// Queue is a FIFO queue on top of SyncList where Put waits for space and
+// Take waits for data instead of returning is_present=false.
+type Queue[T any] struct {
+	list     *SyncList[T]
+	capacity uint32
+	closed   bool
+	mutex    *sync.Mutex
+	change   signal.Signal
+}
+
+// create a queue holding at most capacity elements; 0 means unbounded
+func CreateQueue[T any](capacity uint32) *Queue[T] {
+	q := new(Queue[T])
+	q.list = CreateSync[T]()
+	q.capacity = capacity
+	q.closed = false
+	q.mutex = &sync.Mutex{}
+	return q
+}
+
+// returns the number of elements in the queue
+func (q *Queue[T]) Len() uint32 {
+	return q.list.Len()
+}
+
+// returns the capacity of the queue; 0 means unbounded
+func (q *Queue[T]) Capacity() uint32 {
+	return q.capacity
+}
+
+// add v to the end of the queue, waiting for space if the queue is full
+func (q *Queue[T]) Put(ctx context.Context, v T) error {
+	doneC := ctx.Done()
+	for {
+		q.mutex.Lock()
+		if q.closed {
+			q.mutex.Unlock()
+			return ErrQueueClosed
+		}
+		if q.capacity == 0 || q.list.Len() < q.capacity {
+			q.list.Append(v)
+			q.change.Broadcast()
+			q.mutex.Unlock()
+			return nil
+		}
+		changeC := q.change.Wait()
+		q.mutex.Unlock()
+
+		select {
+		case <-doneC:
+			return ctx.Err()
+		case <-changeC:
+		}
+	}
+}
+
+// take up to max elements if there are any; the mutex must be held
+func (q *Queue[T]) take(max int) []T {
+	ans := make([]T, 0)
+	for len(ans) < max {
+		v, present := q.list.Pop()
+		if !present {
+			break
+		}
+		ans = append(ans, v)
+	}
+	if 0 < len(ans) {
+		q.change.Broadcast()
+	}
+	return ans
+}
+
+// remove and return the first element, waiting until one is available.
+// once the queue is closed and drained, ErrQueueClosed is returned.
+func (q *Queue[T]) Take(ctx context.Context) (ans T, err error) {
+	doneC := ctx.Done()
+	for {
+		q.mutex.Lock()
+		v, present := q.list.Pop()
+		if present {
+			q.change.Broadcast()
+			q.mutex.Unlock()
+			ans = v
+			return
+		}
+		if q.closed {
+			q.mutex.Unlock()
+			err = ErrQueueClosed
+			return
+		}
+		changeC := q.change.Wait()
+		q.mutex.Unlock()
+
+		select {
+		case <-doneC:
+			err = ctx.Err()
+			return
+		case <-changeC:
+		}
+	}
+}
+
+// wait for at least one element, then keep collecting until max elements
+// have been taken or timeout has passed since the first one arrived.
+// An error is only returned if nothing was taken.
+func (q *Queue[T]) TakeBatch(ctx context.Context, max int, timeout time.Duration) ([]T, error) {
+	if max <= 0 {
+		return nil, errors.New("max must be positive")
+	}
+	first, err := q.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ans := []T{first}
+	doneC := ctx.Done()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(ans) < max {
+		q.mutex.Lock()
+		ans = append(ans, q.take(max-len(ans))...)
+		if len(ans) == max || q.closed {
+			q.mutex.Unlock()
+			break
+		}
+		changeC := q.change.Wait()
+		q.mutex.Unlock()
+
+		select {
+		case <-doneC:
+			return ans, nil
+		case <-timer.C:
+			return ans, nil
+		case <-changeC:
+		}
+	}
+	return ans, nil
+}
+
+// close the queue and wake up all waiters.
+// Put fails from here on; Take drains what is left.
+func (q *Queue[T]) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.change.Broadcast()
+}
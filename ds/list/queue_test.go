@@ -0,0 +1,105 @@
+package list_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+	q := ll.CreateQueue[int](4)
+	N := 1000
+	workers := 4
+
+	wg := &sync.WaitGroup{}
+	sums := make([]int, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for {
+				list, err := q.TakeBatch(ctx, 3, time.Millisecond)
+				if errors.Is(err, ll.ErrQueueClosed) {
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				assert.LessOrEqual(t, len(list), 3)
+				for _, v := range list {
+					sums[w] += v
+				}
+			}
+		}(w)
+	}
+	for i := 1; i <= N; i++ {
+		assert.Nil(t, q.Put(ctx, i))
+		assert.LessOrEqual(t, q.Len(), uint32(4))
+	}
+	q.Close()
+	wg.Wait()
+
+	total := 0
+	for _, s := range sums {
+		total += s
+	}
+	assert.Equal(t, N*(N+1)/2, total)
+}
+
+func TestQueueBlocking(t *testing.T) {
+	q := ll.CreateQueue[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := q.Take(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Nil(t, q.Put(context.Background(), 1))
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	assert.ErrorIs(t, q.Put(ctx2, 2), context.DeadlineExceeded)
+
+	// closing wakes a blocked Put, and what is queued can still be taken
+	errC := make(chan error, 1)
+	go func() {
+		errC <- q.Put(context.Background(), 3)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	select {
+	case err = <-errC:
+		assert.ErrorIs(t, err, ll.ErrQueueClosed)
+	case <-time.After(3 * time.Second):
+		t.Fatal("time out")
+	}
+	v, err := q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+	_, err = q.Take(context.Background())
+	assert.ErrorIs(t, err, ll.ErrQueueClosed)
+}
+
+func TestQueueNoWaiterAllocs(t *testing.T) {
+	plain := ll.CreateSync[int]()
+	expected := testing.AllocsPerRun(100, func() {
+		plain.Append(1)
+		plain.Pop()
+	})
+	q := ll.CreateQueue[int](4)
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		q.Put(ctx, 1)
+		q.Take(ctx)
+	})
+	// with nobody waiting, only the list itself may allocate
+	assert.Equal(t, expected, allocs)
+}
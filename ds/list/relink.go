@@ -0,0 +1,215 @@
+package list
+
+// The methods in this file only relink existing nodes; none of them
+// allocate a node.
+
+// detach node from its neighbours without changing Size
+func (g *Generic[T]) unlink(node *Node[T]) {
+	if node.prev == nil {
+		g.head = node.next
+	} else {
+		node.prev.next = node.next
+	}
+	if node.next == nil {
+		g.tail = node.prev
+	} else {
+		node.next.prev = node.prev
+	}
+	node.prev = nil
+	node.next = nil
+}
+
+// put a detached node directly after mark; a nil mark means the front
+func (g *Generic[T]) linkAfter(node *Node[T], mark *Node[T]) {
+	var next *Node[T]
+	if mark == nil {
+		next = g.head
+		g.head = node
+	} else {
+		next = mark.next
+		mark.next = node
+	}
+	node.prev = mark
+	node.next = next
+	if next == nil {
+		g.tail = node
+	} else {
+		next.prev = node
+	}
+}
+
+// move node to the start of the list
+func (g *Generic[T]) MoveToFront(node *Node[T]) error {
+	if err := g.checkNode(node); err != nil {
+		return err
+	}
+	if g.head != node {
+		g.unlink(node)
+		g.linkAfter(node, nil)
+	}
+	return nil
+}
+
+// move node to the end of the list
+func (g *Generic[T]) MoveToBack(node *Node[T]) error {
+	if err := g.checkNode(node); err != nil {
+		return err
+	}
+	if g.tail != node {
+		g.unlink(node)
+		g.linkAfter(node, g.tail)
+	}
+	return nil
+}
+
+// move node so that it sits directly before mark
+func (g *Generic[T]) MoveBefore(node *Node[T], mark *Node[T]) error {
+	if err := g.checkNode(node); err != nil {
+		return err
+	}
+	if err := g.checkNode(mark); err != nil {
+		return err
+	}
+	if node != mark && node.next != mark {
+		g.unlink(node)
+		g.linkAfter(node, mark.prev)
+	}
+	return nil
+}
+
+// move node so that it sits directly after mark
+func (g *Generic[T]) MoveAfter(node *Node[T], mark *Node[T]) error {
+	if err := g.checkNode(node); err != nil {
+		return err
+	}
+	if err := g.checkNode(mark); err != nil {
+		return err
+	}
+	if node != mark && mark.next != node {
+		g.unlink(node)
+		g.linkAfter(node, mark)
+	}
+	return nil
+}
+
+// move all nodes of other to the end of this list, leaving other empty.
+// Handing over ownership of the nodes takes O(len(other)).
+func (g *Generic[T]) PushListBack(other *Generic[T]) {
+	if other == nil || other == g || other.head == nil {
+		return
+	}
+	first := other.head
+	for node := first; node != nil; node = node.next {
+		node.list = g
+	}
+	if g.tail == nil {
+		g.head = first
+	} else {
+		g.tail.next = first
+		first.prev = g.tail
+	}
+	g.tail = other.tail
+	g.Size += other.Size
+
+	other.head = nil
+	other.tail = nil
+	other.Size = 0
+
+	// observers see both lists in their final state
+	for node := first; node != nil; node = node.next {
+		other.notifyRemoved(node.value)
+		g.notifyInserted(node)
+	}
+}
+
+// cut the list in two before node. node and everything after it are moved
+// to the returned list; this list keeps everything before node.
+func (g *Generic[T]) SplitAt(node *Node[T]) (*Generic[T], error) {
+	if err := g.checkNode(node); err != nil {
+		return nil, err
+	}
+	ans := CreateGeneric[T]()
+	var count uint32 = 0
+	for x := node; x != nil; x = x.next {
+		x.list = ans
+		count++
+	}
+	ans.head = node
+	ans.tail = g.tail
+	ans.Size = count
+
+	g.tail = node.prev
+	if g.tail == nil {
+		g.head = nil
+	} else {
+		g.tail.next = nil
+	}
+	node.prev = nil
+	g.Size -= count
+
+	for x := node; x != nil; x = x.next {
+		g.notifyRemoved(x.value)
+	}
+	return ans, nil
+}
+
+// reverse the order of the list
+func (g *Generic[T]) Reverse() {
+	for node := g.head; node != nil; node = node.prev {
+		node.next, node.prev = node.prev, node.next
+	}
+	g.head, g.tail = g.tail, g.head
+}
+
+// stable in-place merge sort, O(n log n)
+func (g *Generic[T]) Sort(compare CompareCallback[T]) {
+	if g.head == nil {
+		return
+	}
+	list := g.head
+	for k := 1; ; k *= 2 {
+		p := list
+		list = nil
+		var tail *Node[T]
+		merges := 0
+		for p != nil {
+			merges++
+			// p runs for psize nodes, q for up to k nodes after that
+			q := p
+			psize := 0
+			for i := 0; i < k && q != nil; i++ {
+				psize++
+				q = q.next
+			}
+			qsize := k
+			for 0 < psize || (0 < qsize && q != nil) {
+				var e *Node[T]
+				if psize == 0 {
+					e, q = q, q.next
+					qsize--
+				} else if qsize == 0 || q == nil || compare(p.value, q.value) <= 0 {
+					// take from p on ties to keep the sort stable
+					e, p = p, p.next
+					psize--
+				} else {
+					e, q = q, q.next
+					qsize--
+				}
+				if tail == nil {
+					list = e
+				} else {
+					tail.next = e
+				}
+				e.prev = tail
+				tail = e
+			}
+			p = q
+		}
+		tail.next = nil
+		if merges <= 1 {
+			g.head = list
+			g.tail = tail
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package list_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+// walk the list both ways and check it agrees with expected
+func assertList(t *testing.T, expected []int, q *ll.Generic[int]) {
+	assert.Nil(t, q.Validate())
+	assert.Equal(t, len(expected), int(q.Size), "size")
+	assert.True(t, isEqual(expected, q.Array()), "forward %v vs %v", expected, q.Array())
+	i := len(expected) - 1
+	for node := q.TailNode(); node != nil; node = node.Prev() {
+		assert.Equal(t, expected[i], node.Value(), "backward")
+		i--
+	}
+	assert.Equal(t, -1, i)
+}
+
+func TestSortStable(t *testing.T) {
+	type pair struct {
+		key   int
+		order int
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 3, 7, 64, 1000} {
+		q := ll.CreateGeneric[pair]()
+		ref := make([]pair, n)
+		for i := 0; i < n; i++ {
+			ref[i] = pair{key: rng.Intn(10), order: i}
+			q.Append(ref[i])
+		}
+		sort.SliceStable(ref, func(i, j int) bool { return ref[i].key < ref[j].key })
+		q.Sort(func(a pair, b pair) int { return scb(a.key, b.key) })
+		assert.Equal(t, ref, q.Array())
+		if 0 < n {
+			tail, _ := q.Tail()
+			assert.Equal(t, ref[n-1], tail)
+			assert.Nil(t, q.HeadNode().Prev())
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	q := ll.CreateGeneric[int]()
+	nodes := make([]*ll.Node[int], 5)
+	for i := range nodes {
+		nodes[i] = q.Append(i)
+	}
+	q.MoveToFront(nodes[3])
+	assertList(t, []int{3, 0, 1, 2, 4}, q)
+	q.MoveToBack(nodes[3])
+	assertList(t, []int{0, 1, 2, 4, 3}, q)
+	q.MoveBefore(nodes[4], nodes[0])
+	assertList(t, []int{4, 0, 1, 2, 3}, q)
+	q.MoveAfter(nodes[4], nodes[3])
+	assertList(t, []int{0, 1, 2, 3, 4}, q)
+	q.MoveAfter(nodes[0], nodes[1])
+	assertList(t, []int{1, 0, 2, 3, 4}, q)
+	q.MoveBefore(nodes[1], nodes[0])
+	assertList(t, []int{1, 0, 2, 3, 4}, q)
+	q.MoveToBack(nodes[4])
+	assertList(t, []int{1, 0, 2, 3, 4}, q)
+}
+
+func TestSplitConcatReverse(t *testing.T) {
+	q := ll.CreateGeneric[int]()
+	q.AppendArray([]int{0, 1, 2, 3, 4, 5})
+	mid := q.HeadNode().Next().Next().Next()
+
+	back, err := q.SplitAt(mid)
+	assert.Nil(t, err)
+	assertList(t, []int{0, 1, 2}, q)
+	assertList(t, []int{3, 4, 5}, back)
+
+	back.Reverse()
+	assertList(t, []int{5, 4, 3}, back)
+
+	q.PushListBack(back)
+	assertList(t, []int{0, 1, 2, 5, 4, 3}, q)
+	assertList(t, []int{}, back)
+
+	all, err := q.SplitAt(q.HeadNode())
+	assert.Nil(t, err)
+	assertList(t, []int{}, q)
+	assertList(t, []int{0, 1, 2, 5, 4, 3}, all)
+	q.PushListBack(all)
+	q.Append(6)
+	assertList(t, []int{0, 1, 2, 5, 4, 3, 6}, q)
+
+	empty := ll.CreateGeneric[int]()
+	empty.Reverse()
+	assertList(t, []int{}, empty)
+}
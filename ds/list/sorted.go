@@ -0,0 +1,255 @@
+package list
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	SORTED_MAX_LEVEL = 32
+	// chance of a node reaching the next level
+	sortedLevelP = 0.25
+)
+
+type sortedNode[T any] struct {
+	value T
+	next  []*sortedNode[T]
+	// span[i] is the number of level 0 steps from this node to next[i]
+	span []uint32
+	prev *sortedNode[T]
+}
+
+// Sorted is an indexable skip list ordered by a CompareCallback. Insert,
+// Delete, Find, Rank and Get take expected O(log n) time; iteration is in
+// order. Equal elements are kept in insertion order.
+//
+// Sorted is not safe for concurrent use; see SyncSorted.
+type Sorted[T any] struct {
+	head    *sortedNode[T]
+	tail    *sortedNode[T]
+	level   int
+	size    uint32
+	compare CompareCallback[T]
+	rand    *rand.Rand
+}
+
+func CreateSorted[T any](compare CompareCallback[T]) *Sorted[T] {
+	s := new(Sorted[T])
+	s.head = &sortedNode[T]{
+		next: make([]*sortedNode[T], SORTED_MAX_LEVEL),
+		span: make([]uint32, SORTED_MAX_LEVEL),
+	}
+	s.tail = nil
+	s.level = 1
+	s.size = 0
+	s.compare = compare
+	s.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	return s
+}
+
+func (s *Sorted[T]) randomLevel() int {
+	level := 1
+	for level < SORTED_MAX_LEVEL && s.rand.Float64() < sortedLevelP {
+		level++
+	}
+	return level
+}
+
+// returns the number of elements in the list
+func (s *Sorted[T]) Len() uint32 {
+	return s.size
+}
+
+// insert x after any elements equal to it
+func (s *Sorted[T]) Insert(x T) {
+	var update [SORTED_MAX_LEVEL]*sortedNode[T]
+	var rank [SORTED_MAX_LEVEL]uint32
+	node := s.head
+	for i := s.level - 1; 0 <= i; i-- {
+		if i < s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for node.next[i] != nil && s.compare(node.next[i].value, x) <= 0 {
+			rank[i] += node.span[i]
+			node = node.next[i]
+		}
+		update[i] = node
+	}
+
+	level := s.randomLevel()
+	if s.level < level {
+		for i := s.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = s.head
+			update[i].span[i] = s.size
+		}
+		s.level = level
+	}
+
+	n := &sortedNode[T]{
+		value: x,
+		next:  make([]*sortedNode[T], level),
+		span:  make([]uint32, level),
+	}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+		n.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < s.level; i++ {
+		update[i].span[i]++
+	}
+
+	if update[0] != s.head {
+		n.prev = update[0]
+	}
+	if n.next[0] != nil {
+		n.next[0].prev = n
+	} else {
+		s.tail = n
+	}
+	s.size++
+}
+
+// find the last node less than x on every level
+func (s *Sorted[T]) before(x T, update *[SORTED_MAX_LEVEL]*sortedNode[T]) *sortedNode[T] {
+	node := s.head
+	for i := s.level - 1; 0 <= i; i-- {
+		for node.next[i] != nil && s.compare(node.next[i].value, x) < 0 {
+			node = node.next[i]
+		}
+		if update != nil {
+			update[i] = node
+		}
+	}
+	return node
+}
+
+// remove the first element equal to x; returns false if there is none
+func (s *Sorted[T]) Delete(x T) bool {
+	var update [SORTED_MAX_LEVEL]*sortedNode[T]
+	n := s.before(x, &update).next[0]
+	if n == nil || s.compare(n.value, x) != 0 {
+		return false
+	}
+	for i := 0; i < s.level; i++ {
+		if update[i].next[i] == n {
+			update[i].span[i] += n.span[i] - 1
+			update[i].next[i] = n.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	if n.next[0] != nil {
+		n.next[0].prev = n.prev
+	} else {
+		s.tail = n.prev
+	}
+	for 1 < s.level && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.size--
+	return true
+}
+
+// returns the first element equal to x
+func (s *Sorted[T]) Find(x T) (ans T, is_present bool) {
+	n := s.before(x, nil).next[0]
+	if n == nil || s.compare(n.value, x) != 0 {
+		return
+	}
+	return n.value, true
+}
+
+// returns the number of elements less than x, which is the index x
+// has or would have in the list
+func (s *Sorted[T]) Rank(x T) uint32 {
+	var rank uint32 = 0
+	node := s.head
+	for i := s.level - 1; 0 <= i; i-- {
+		for node.next[i] != nil && s.compare(node.next[i].value, x) < 0 {
+			rank += node.span[i]
+			node = node.next[i]
+		}
+	}
+	return rank
+}
+
+// returns the Ith element in order
+func (s *Sorted[T]) Get(index uint32) (ans T, is_present bool) {
+	if s.size <= index {
+		return
+	}
+	var traversed uint32 = 0
+	node := s.head
+	for i := s.level - 1; 0 <= i; i-- {
+		for node.next[i] != nil && traversed+node.span[i] <= index+1 {
+			traversed += node.span[i]
+			node = node.next[i]
+		}
+		if traversed == index+1 {
+			return node.value, true
+		}
+	}
+	return
+}
+
+func (s *Sorted[T]) Min() (ans T, is_present bool) {
+	if s.head.next[0] == nil {
+		return
+	}
+	return s.head.next[0].value, true
+}
+
+func (s *Sorted[T]) Max() (ans T, is_present bool) {
+	if s.tail == nil {
+		return
+	}
+	return s.tail.value, true
+}
+
+// walk the elements in order, stopping at the first error
+func (s *Sorted[T]) Iterate(callback func(obj T, index uint32) error) error {
+	var i uint32 = 0
+	for node := s.head.next[0]; node != nil; node = node.next[0] {
+		if err := callback(node.value, i); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// walk the elements in reverse order, stopping at the first error
+func (s *Sorted[T]) IterateReverse(callback func(obj T, index uint32) error) error {
+	i := s.size - 1
+	for node := s.tail; node != nil; node = node.prev {
+		if err := callback(node.value, i); err != nil {
+			return err
+		}
+		i--
+	}
+	return nil
+}
+
+// walk the elements in [from, to) in order, stopping at the first error
+func (s *Sorted[T]) Range(from T, to T, callback func(obj T) error) error {
+	for node := s.before(from, nil).next[0]; node != nil; node = node.next[0] {
+		if 0 <= s.compare(node.value, to) {
+			break
+		}
+		if err := callback(node.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Sorted[T]) Array() []T {
+	ans := make([]T, 0, s.size)
+	for node := s.head.next[0]; node != nil; node = node.next[0] {
+		ans = append(ans, node.value)
+	}
+	return ans
+}
@@ -0,0 +1,92 @@
+package list
+
+import "sync"
+
+// SyncSorted is a Sorted list guarded by a read-write lock.
+// Callbacks passed to Iterate and Range run under the read lock and must
+// not modify the list.
+type SyncSorted[T any] struct {
+	s     *Sorted[T]
+	mutex *sync.RWMutex
+}
+
+func CreateSyncSorted[T any](compare CompareCallback[T]) *SyncSorted[T] {
+	return &SyncSorted[T]{s: CreateSorted(compare), mutex: &sync.RWMutex{}}
+}
+
+func (g *SyncSorted[T]) Len() uint32 {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Len()
+}
+
+func (g *SyncSorted[T]) Insert(x T) {
+	g.mutex.Lock()
+	g.s.Insert(x)
+	g.mutex.Unlock()
+}
+
+func (g *SyncSorted[T]) Delete(x T) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.s.Delete(x)
+}
+
+func (g *SyncSorted[T]) Find(x T) (T, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Find(x)
+}
+
+func (g *SyncSorted[T]) Rank(x T) uint32 {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Rank(x)
+}
+
+func (g *SyncSorted[T]) Get(index uint32) (T, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Get(index)
+}
+
+func (g *SyncSorted[T]) Min() (T, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Min()
+}
+
+func (g *SyncSorted[T]) Max() (T, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Max()
+}
+
+// remove and return the smallest element
+func (g *SyncSorted[T]) PopMin() (ans T, is_present bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	ans, is_present = g.s.Min()
+	if is_present {
+		g.s.Delete(ans)
+	}
+	return
+}
+
+func (g *SyncSorted[T]) Iterate(callback func(obj T, index uint32) error) error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Iterate(callback)
+}
+
+func (g *SyncSorted[T]) Range(from T, to T, callback func(obj T) error) error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Range(from, to, callback)
+}
+
+func (g *SyncSorted[T]) Array() []T {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.s.Array()
+}
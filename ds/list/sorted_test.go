@@ -0,0 +1,110 @@
+package list_test
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedRandom(t *testing.T) {
+	s := ll.CreateSorted(scb)
+	ref := make([]int, 0)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		v := rng.Intn(500)
+		if rng.Intn(3) == 0 {
+			idx := sort.SearchInts(ref, v)
+			present := idx < len(ref) && ref[idx] == v
+			assert.Equal(t, present, s.Delete(v), "delete %d", v)
+			if present {
+				ref = append(ref[:idx], ref[idx+1:]...)
+			}
+		} else {
+			s.Insert(v)
+			idx := sort.SearchInts(ref, v+1)
+			ref = append(ref[:idx], append([]int{v}, ref[idx:]...)...)
+		}
+	}
+	assert.Equal(t, uint32(len(ref)), s.Len())
+	assert.True(t, isEqual(ref, s.Array()), "order does not match")
+
+	for _, v := range []int{-1, 0, 17, 250, 499, 600} {
+		assert.Equal(t, uint32(sort.SearchInts(ref, v)), s.Rank(v), "rank %d", v)
+		_, ok := s.Find(v)
+		idx := sort.SearchInts(ref, v)
+		assert.Equal(t, idx < len(ref) && ref[idx] == v, ok)
+	}
+	for i := 0; i < len(ref); i += 37 {
+		v, ok := s.Get(uint32(i))
+		assert.True(t, ok)
+		assert.Equal(t, ref[i], v)
+	}
+	_, ok := s.Get(uint32(len(ref)))
+	assert.False(t, ok)
+
+	min, _ := s.Min()
+	max, _ := s.Max()
+	assert.Equal(t, ref[0], min)
+	assert.Equal(t, ref[len(ref)-1], max)
+
+	got := make([]int, 0)
+	assert.Nil(t, s.Range(100, 200, func(obj int) error {
+		got = append(got, obj)
+		return nil
+	}))
+	assert.True(t, isEqual(ref[sort.SearchInts(ref, 100):sort.SearchInts(ref, 200)], got))
+
+	reversed := make([]int, 0)
+	s.IterateReverse(func(obj int, index uint32) error {
+		assert.Equal(t, ref[index], obj)
+		reversed = append(reversed, obj)
+		return nil
+	})
+	assert.Equal(t, len(ref), len(reversed))
+}
+
+func TestSyncSorted(t *testing.T) {
+	s := ll.CreateSyncSorted(scb)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				s.Insert(w*1000 + i)
+				s.Rank(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.Equal(t, uint32(2000), s.Len())
+	v, ok := s.PopMin()
+	assert.True(t, ok)
+	assert.Equal(t, 0, v)
+	arr := s.Array()
+	assert.True(t, sort.IntsAreSorted(arr))
+}
+
+func BenchmarkInsertSortedGeneric(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		q := ll.CreateGeneric[int]()
+		for j := 0; j < 2000; j++ {
+			q.InsertSorted(rng.Int(), scb)
+		}
+	}
+}
+
+func BenchmarkInsertSorted(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		s := ll.CreateSorted(scb)
+		for j := 0; j < 2000; j++ {
+			s.Insert(rng.Int())
+		}
+	}
+}
@@ -1,12 +1,27 @@
 package list
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
 
+// SyncList is a linked list that can be shared between goroutines.
+// Every method takes the list lock. Iterate and IterateReverse run the
+// callback over a snapshot taken under a read lock, so the callback may
+// call back into the list; deletes requested by the callback are applied
+// after the walk.
+//
+// Read Size only while no other goroutine is writing to the list;
+// otherwise use Len.
 type SyncList[T any] struct {
-	Size  uint32
-	head  *SyncNode[T]
-	tail  *SyncNode[T]
-	mutex *sync.Mutex
+	Size      uint32
+	head      *SyncNode[T]
+	tail      *SyncNode[T]
+	mutex     *sync.RWMutex
+	observers []*observerEntry[SyncObserver[T]]
+	// serializes Updated events, which are sent under the read lock
+	notifyMutex *sync.Mutex
 }
 
 func CreateSync[T any]() *SyncList[T] {
@@ -14,15 +29,31 @@ func CreateSync[T any]() *SyncList[T] {
 	g.Size = 0
 	g.head = nil
 	g.tail = nil
-	g.mutex = &sync.Mutex{}
+	g.mutex = &sync.RWMutex{}
+	g.notifyMutex = &sync.Mutex{}
 	return g
 }
 
+// returns the number of elements in the list
+func (g *SyncList[T]) Len() uint32 {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.Size
+}
+
 // attach obj to the end of a linked list
 func (g *SyncList[T]) Append(v T) *SyncNode[T] {
+	node := CreateBlankSyncNode(v)
 	g.mutex.Lock()
+	g.append(node)
+	g.mutex.Unlock()
+	return node
+}
+
+// the mutex must be held
+func (g *SyncList[T]) append(node *SyncNode[T]) {
 	g.Size++
-	node := CreateBlankSyncNode(v)
+	node.list = g
 	if g.tail == nil {
 		g.head = node
 		g.tail = node
@@ -32,99 +63,150 @@ func (g *SyncList[T]) Append(v T) *SyncNode[T] {
 		oldTail.next = node
 		g.tail = node
 	}
-	g.mutex.Unlock()
-	return node
+	g.notifyInserted(node)
 }
 
 func (g *SyncList[T]) Head() (ans T, is_present bool) {
-	g.mutex.Lock()
+	g.mutex.RLock()
 	if g.head == nil {
 		is_present = false
 	} else {
 		is_present = true
-		ans = g.head.value
+		ans = g.head.Value()
 	}
-	g.mutex.Unlock()
+	g.mutex.RUnlock()
 	return
 }
 
 func (g *SyncList[T]) HeadNode() *SyncNode[T] {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
 	return g.head
 }
 
 func (g *SyncList[T]) TailNode() *SyncNode[T] {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
 	return g.tail
 }
 
 // remove and return the first element of the linked list
 func (g *SyncList[T]) Pop() (ans T, is_present bool) {
-
+	g.mutex.Lock()
 	head := g.head
 	if head == nil {
 		is_present = false
 	} else {
-		ans = head.value
-		g.Remove(head)
+		ans = head.Value()
+		g.remove(head)
 		is_present = true
 	}
-
+	g.mutex.Unlock()
 	return
 }
 
 func (g *SyncList[T]) Tail() (ans T, is_present bool) {
-	g.mutex.Lock()
+	g.mutex.RLock()
 	if g.tail == nil {
 		is_present = false
 	} else {
 		is_present = true
-		ans = g.tail.value
+		ans = g.tail.Value()
 	}
-	g.mutex.Unlock()
+	g.mutex.RUnlock()
 	return
 }
 
+// copy out the nodes under a read lock
+func (g *SyncList[T]) snapshot() []*SyncNode[T] {
+	g.mutex.RLock()
+	list := make([]*SyncNode[T], 0, g.Size)
+	for node := g.head; node != nil; node = node.next {
+		list = append(list, node)
+	}
+	g.mutex.RUnlock()
+	return list
+}
+
+// remove the nodes marked for deletion during an iteration
+func (g *SyncList[T]) removeAll(deleteList []*SyncNode[T]) {
+	if len(deleteList) == 0 {
+		return
+	}
+	g.mutex.Lock()
+	for _, node := range deleteList {
+		g.remove(node)
+	}
+	g.mutex.Unlock()
+}
+
 func (g *SyncList[T]) Iterate(callback func(obj T, index uint32, delete func()) error) error {
-	var i uint32 = 0
 	var err error
-	for node := g.head; node != nil; node = node.next {
-		err = callback(node.value, i, func() { g.Remove(node) })
+	list := g.snapshot()
+	deleteList := make([]*SyncNode[T], 0)
+	for i, node := range list {
+		n := node
+		// do not remove nodes until the iteration is complete
+		err = callback(n.Value(), uint32(i), func() { deleteList = append(deleteList, n) })
 		if err != nil {
-			return err
+			break
 		}
-		i++
 	}
-	return nil
+	g.removeAll(deleteList)
+	return err
 }
 
 func (g *SyncList[T]) IterateReverse(callback func(obj T, index uint32, delete func()) error) error {
-	var i uint32 = g.Size - 1
 	var err error
-	for node := g.tail; node != nil; node = node.prev {
-		err = callback(node.value, i, func() {
-			g.Remove(node)
-		})
+	list := g.snapshot()
+	deleteList := make([]*SyncNode[T], 0)
+	for i := len(list) - 1; 0 <= i; i-- {
+		n := list[i]
+		err = callback(n.Value(), uint32(i), func() { deleteList = append(deleteList, n) })
 		if err != nil {
-			return err
+			break
 		}
-		i--
 	}
-	return nil
+	g.removeAll(deleteList)
+	return err
 }
 
 func (g *SyncList[T]) Array() []T {
-	ans := make([]T, g.Size)
-	g.Iterate(func(obj T, index uint32, delete func()) error {
-		ans[index] = obj
-		return nil
-	})
+	g.mutex.RLock()
+	ans := make([]T, 0, g.Size)
+	for node := g.head; node != nil; node = node.next {
+		ans = append(ans, node.Value())
+	}
+	g.mutex.RUnlock()
 	return ans
 }
 
-func (g *SyncList[T]) Remove(node *SyncNode[T]) {
+// returns an error if the node belongs to another list or has already
+// been removed; the mutex must be held
+func (g *SyncList[T]) checkNode(node *SyncNode[T]) error {
 	if node == nil {
-		return
+		return errors.New("node is nil")
+	}
+	if node.list != g {
+		return errors.New("node belongs to another list")
+	}
+	if node.removed {
+		return errors.New("node has been removed")
 	}
+	return nil
+}
+
+func (g *SyncList[T]) Remove(node *SyncNode[T]) error {
 	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.remove(node)
+}
+
+// the mutex must be held
+func (g *SyncList[T]) remove(node *SyncNode[T]) error {
+	if err := g.checkNode(node); err != nil {
+		return err
+	}
 	prevNode := node.prev
 	nextNode := node.next
 
@@ -144,59 +226,142 @@ func (g *SyncList[T]) Remove(node *SyncNode[T]) {
 		prevNode.next = nextNode
 		nextNode.prev = prevNode
 	}
-	g.mutex.Unlock()
+	node.removed = true
+	node.prev = nil
+	node.next = nil
+	g.notifyRemoved(node.Value())
+	return nil
 }
 
-func (g *SyncList[T]) Insert(v T, prevNode *SyncNode[T]) *SyncNode[T] {
-	if prevNode == nil {
-		return nil
-	}
+// insert after prevNode
+func (g *SyncList[T]) Insert(v T, prevNode *SyncNode[T]) (*SyncNode[T], error) {
+	middleNode := CreateBlankSyncNode(v)
 	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if err := g.checkNode(prevNode); err != nil {
+		return nil, err
+	}
 
-	middleNode := CreateBlankSyncNode(v)
-	g.Size++
-	nextNode := prevNode.Next()
+	nextNode := prevNode.next
 	if nextNode == nil {
-		oldTail := g.tail
-		g.tail = middleNode
-		middleNode.prev = oldTail
-		oldTail.next = middleNode
+		g.append(middleNode)
 	} else {
+		g.Size++
+		middleNode.list = g
 		middleNode.prev = prevNode
 		middleNode.next = nextNode
 		prevNode.next = middleNode
 		nextNode.prev = middleNode
+		g.notifyInserted(middleNode)
 	}
-	g.mutex.Unlock()
-	return middleNode
+	return middleNode, nil
+}
+
+// check the links agree with each other, with head and tail, and with Size
+func (g *SyncList[T]) Validate() error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	if (g.head == nil) != (g.tail == nil) {
+		return errors.New("only one of head and tail is nil")
+	}
+	if g.head != nil && g.head.prev != nil {
+		return errors.New("head has a previous node")
+	}
+	if g.tail != nil && g.tail.next != nil {
+		return errors.New("tail has a next node")
+	}
+	var count uint32 = 0
+	var prev *SyncNode[T]
+	for node := g.head; node != nil; node = node.next {
+		if count == g.Size {
+			return fmt.Errorf("more than %d nodes, or the links form a cycle", g.Size)
+		}
+		if node.list != g || node.removed {
+			return fmt.Errorf("node %d does not belong to this list", count)
+		}
+		if node.prev != prev {
+			return fmt.Errorf("node %d has a broken prev link", count)
+		}
+		prev = node
+		count++
+	}
+	if prev != g.tail {
+		return errors.New("last node is not the tail")
+	}
+	if count != g.Size {
+		return fmt.Errorf("size is %d but there are %d nodes", g.Size, count)
+	}
+	return nil
 }
 
 type SyncNode[T any] struct {
-	next  *SyncNode[T]
-	prev  *SyncNode[T]
-	value T
-	mutex *sync.Mutex
+	next *SyncNode[T]
+	prev *SyncNode[T]
+	// list is set before the node is handed out and never changes;
+	// the links and removed are guarded by the list lock
+	list    *SyncList[T]
+	removed bool
+	value   T
+	mutex   *sync.Mutex
 }
 
 func CreateBlankSyncNode[T any](v T) *SyncNode[T] {
 	return &SyncNode[T]{value: v, mutex: &sync.Mutex{}}
 }
 
+// returns the next node, or nil at the tail or if the node has been removed
 func (n *SyncNode[T]) Next() *SyncNode[T] {
+	if n.list == nil {
+		return nil
+	}
+	n.list.mutex.RLock()
+	defer n.list.mutex.RUnlock()
 	return n.next
 }
 
+// returns the previous node, or nil at the head or if the node has been removed
 func (n *SyncNode[T]) Prev() *SyncNode[T] {
+	if n.list == nil {
+		return nil
+	}
+	n.list.mutex.RLock()
+	defer n.list.mutex.RUnlock()
 	return n.prev
 }
 
 // no copy is done here so be careful
 func (n *SyncNode[T]) Value() T {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
 	return n.value
 }
 
 func (n *SyncNode[T]) ChangeValue(v T) {
+	g := n.list
+	if g == nil {
+		n.swapValue(v)
+		return
+	}
+	// the read lock keeps the node from being removed and the observers
+	// from changing until the event has been sent
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	if len(g.observers) == 0 || n.removed {
+		n.swapValue(v)
+		return
+	}
+	g.notifyMutex.Lock()
+	defer g.notifyMutex.Unlock()
+	old := n.swapValue(v)
+	for _, x := range g.observers {
+		x.o.Updated(n, old, v)
+	}
+}
+
+func (n *SyncNode[T]) swapValue(v T) (old T) {
 	n.mutex.Lock()
+	old = n.value
 	n.value = v
 	n.mutex.Unlock()
+	return
 }
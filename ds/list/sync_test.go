@@ -0,0 +1,105 @@
+package list_test
+
+import (
+	"sync"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+// run with go test -race
+func TestSyncListStress(t *testing.T) {
+	q := ll.CreateSync[int]()
+	workers := 8
+	N := 300
+
+	wg := &sync.WaitGroup{}
+	popped := make([]int, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				node := q.Append(i)
+				switch i % 5 {
+				case 0:
+					q.Insert(-i, node)
+					q.Remove(node)
+				case 1:
+					if _, ok := q.Pop(); ok {
+						popped[w]++
+					}
+				case 2:
+					q.Iterate(func(obj int, index uint32, deleteNode func()) error {
+						if obj < 0 {
+							deleteNode()
+						}
+						return nil
+					})
+				case 3:
+					for n := q.HeadNode(); n != nil; n = n.Next() {
+						n.ChangeValue(n.Value())
+					}
+				case 4:
+					q.Array()
+					q.IterateReverse(func(obj int, index uint32, deleteNode func()) error {
+						return nil
+					})
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// the links must still agree with each other and with the size
+	assert.Nil(t, q.Validate())
+	arr := q.Array()
+	assert.Equal(t, int(q.Len()), len(arr))
+	reversed := make([]int, 0, len(arr))
+	for n := q.TailNode(); n != nil; n = n.Prev() {
+		reversed = append(reversed, n.Value())
+	}
+	for i := range arr {
+		assert.Equal(t, arr[i], reversed[len(reversed)-1-i])
+	}
+	total := 0
+	for _, p := range popped {
+		total += p
+	}
+	// every Insert is paired with a Remove, so only appends and pops change the count
+	assert.LessOrEqual(t, len(arr), workers*N-total)
+}
+
+func TestSyncListIterateDelete(t *testing.T) {
+	q := ll.CreateSync[int]()
+	for i := 0; i < 6; i++ {
+		q.Append(i)
+	}
+	// the callback can call back into the list without deadlocking
+	assert.Nil(t, q.Iterate(func(obj int, index uint32, deleteNode func()) error {
+		if obj%2 == 0 {
+			deleteNode()
+		}
+		q.Len()
+		return nil
+	}))
+	assert.Equal(t, []int{1, 3, 5}, q.Array())
+	assert.Equal(t, uint32(3), q.Len())
+
+	node := q.HeadNode()
+	assert.Nil(t, q.Remove(node))
+	assert.NotNil(t, q.Remove(node), "removed a node twice")
+	assert.Equal(t, uint32(2), q.Len(), "removing a node twice changed the size")
+	assert.Nil(t, node.Next())
+	_, err := q.Insert(7, node)
+	assert.NotNil(t, err, "inserted after a removed node")
+	other := ll.CreateSync[int]()
+	assert.NotNil(t, other.Remove(q.HeadNode()), "removed a node from another list")
+	assert.Nil(t, q.Validate())
+
+	v, ok := q.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []int{5}, q.Array())
+}
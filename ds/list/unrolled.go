@@ -0,0 +1,356 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+)
+
+// number of elements held by each node of an Unrolled list
+const UNROLLED_NODE_SIZE = 64
+
+type unrolledNode[T any] struct {
+	// items[:n] hold the elements of this node in order
+	items [UNROLLED_NODE_SIZE]T
+	n     int
+	next  *unrolledNode[T]
+	prev  *unrolledNode[T]
+}
+
+// insert x at position i, which must be <= n; the node must not be full
+func (u *unrolledNode[T]) insertAt(i int, x T) {
+	copy(u.items[i+1:u.n+1], u.items[i:u.n])
+	u.items[i] = x
+	u.n++
+}
+
+// remove and return the element at position i
+func (u *unrolledNode[T]) removeAt(i int) T {
+	var blank T
+	x := u.items[i]
+	copy(u.items[i:u.n-1], u.items[i+1:u.n])
+	u.n--
+	u.items[u.n] = blank
+	return x
+}
+
+// Unrolled is a linked list whose nodes each hold up to UNROLLED_NODE_SIZE
+// elements in an array. Compared to Generic it allocates once per node
+// rather than once per element, so iteration touches far fewer cache lines
+// and the garbage collector has far fewer pointers to scan. Elements are
+// addressed by value and index; there are no per-element node handles.
+//
+// A node that is less than half full is merged with a neighbour that has
+// room for its elements, so deletes do not leave the list as a chain of
+// nearly empty nodes.
+type Unrolled[T any] struct {
+	Size uint32
+	head *unrolledNode[T]
+	tail *unrolledNode[T]
+}
+
+func CreateUnrolled[T any]() *Unrolled[T] {
+	u := new(Unrolled[T])
+	u.Size = 0
+	u.head = nil
+	u.tail = nil
+	return u
+}
+
+// link a new empty node after a; a nil a puts it at the head
+func (u *Unrolled[T]) newNode(a *unrolledNode[T]) *unrolledNode[T] {
+	node := new(unrolledNode[T])
+	if a == nil {
+		node.next = u.head
+		if u.head != nil {
+			u.head.prev = node
+		} else {
+			u.tail = node
+		}
+		u.head = node
+		return node
+	}
+	node.prev = a
+	node.next = a.next
+	if a.next != nil {
+		a.next.prev = node
+	} else {
+		u.tail = node
+	}
+	a.next = node
+	return node
+}
+
+func (u *Unrolled[T]) unlinkNode(node *unrolledNode[T]) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		u.head = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		u.tail = node.prev
+	}
+	node.next = nil
+	node.prev = nil
+}
+
+// move the elements of right to the end of left and unlink right; they
+// must fit in left
+func (u *Unrolled[T]) absorb(left *unrolledNode[T], right *unrolledNode[T]) {
+	var blank T
+	copy(left.items[left.n:], right.items[:right.n])
+	left.n += right.n
+	for i := 0; i < right.n; i++ {
+		right.items[i] = blank
+	}
+	right.n = 0
+	u.unlinkNode(right)
+}
+
+// returns true if two neighbouring nodes should be merged: one of them is
+// less than half full and together they fit in one node
+func shouldMerge[T any](a *unrolledNode[T], b *unrolledNode[T]) bool {
+	return (a.n < UNROLLED_NODE_SIZE/2 || b.n < UNROLLED_NODE_SIZE/2) && a.n+b.n <= UNROLLED_NODE_SIZE
+}
+
+// merge node with a neighbour after its size changed; an empty node is
+// unlinked. One merge is enough: the merged node is larger than either of
+// the two, so it cannot fit with the nodes around it.
+func (u *Unrolled[T]) merge(node *unrolledNode[T]) {
+	if node.n == 0 {
+		u.unlinkNode(node)
+		return
+	}
+	if next := node.next; next != nil && shouldMerge(node, next) {
+		u.absorb(node, next)
+	} else if prev := node.prev; prev != nil && shouldMerge(prev, node) {
+		u.absorb(prev, node)
+	}
+}
+
+// move the upper half of a full node into a new node after it
+func (u *Unrolled[T]) split(node *unrolledNode[T]) *unrolledNode[T] {
+	var blank T
+	right := u.newNode(node)
+	half := node.n / 2
+	right.n = copy(right.items[:], node.items[half:node.n])
+	for i := half; i < node.n; i++ {
+		node.items[i] = blank
+	}
+	node.n = half
+	return right
+}
+
+// attach obj to the end of the list
+func (u *Unrolled[T]) Append(obj T) {
+	if u.tail == nil || u.tail.n == UNROLLED_NODE_SIZE {
+		u.newNode(u.tail)
+	}
+	u.tail.insertAt(u.tail.n, obj)
+	u.Size++
+}
+
+// append an array to the end of the list
+func (u *Unrolled[T]) AppendArray(a []T) {
+	for _, x := range a {
+		u.Append(x)
+	}
+}
+
+// attach obj to the start of the list
+func (u *Unrolled[T]) Prepend(obj T) {
+	if u.head == nil || u.head.n == UNROLLED_NODE_SIZE {
+		u.newNode(nil)
+	}
+	u.head.insertAt(0, obj)
+	u.Size++
+}
+
+// insert x before the first element that is not less than x and return
+// the index x ended up at. Whole nodes are skipped by comparing against
+// their last element, so only one node is searched element by element.
+func (u *Unrolled[T]) InsertSorted(x T, compare CompareCallback[T]) uint32 {
+	var index uint32 = 0
+	node := u.head
+	for node != nil && compare(node.items[node.n-1], x) < 0 {
+		index += uint32(node.n)
+		node = node.next
+	}
+	if node == nil {
+		u.Append(x)
+		return u.Size - 1
+	}
+	// binary search for the first element not less than x
+	lo, hi := 0, node.n
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if compare(node.items[mid], x) < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if node.n == UNROLLED_NODE_SIZE {
+		left := node
+		right := u.split(left)
+		if left.n < lo {
+			lo -= left.n
+			index += uint32(left.n)
+			node = right
+		}
+		node.insertAt(lo, x)
+		// the halves may now fit with small nodes on either side
+		u.merge(right)
+		u.merge(left)
+	} else {
+		node.insertAt(lo, x)
+	}
+	u.Size++
+	return index + uint32(lo)
+}
+
+func (u *Unrolled[T]) Head() (ans T, is_present bool) {
+	if u.head == nil {
+		return
+	}
+	return u.head.items[0], true
+}
+
+func (u *Unrolled[T]) Tail() (ans T, is_present bool) {
+	if u.tail == nil {
+		return
+	}
+	return u.tail.items[u.tail.n-1], true
+}
+
+// remove and return the first element of the list
+func (u *Unrolled[T]) Pop() (ans T, is_present bool) {
+	if u.head == nil {
+		return
+	}
+	node := u.head
+	ans = node.removeAt(0)
+	u.merge(node)
+	u.Size--
+	return ans, true
+}
+
+// returns the Ith element of the list
+func (u *Unrolled[T]) Get(index uint32) (ans T, is_present bool) {
+	if u.Size <= index {
+		return
+	}
+	i := int(index)
+	for node := u.head; node != nil; node = node.next {
+		if i < node.n {
+			return node.items[i], true
+		}
+		i -= node.n
+	}
+	return
+}
+
+func (u *Unrolled[T]) Iterate(callback func(obj T, index uint32, deleteNode func()) error) error {
+	var i uint32 = 0
+	var err error
+	deleteList := make([]uint32, 0)
+	for node := u.head; node != nil; node = node.next {
+		for k := 0; k < node.n; k++ {
+			index := i
+			// do not remove elements until the iteration is complete
+			err = callback(node.items[k], i, func() { deleteList = append(deleteList, index) })
+			if err != nil {
+				return err
+			}
+			i++
+		}
+	}
+	u.removeIndexes(deleteList)
+	return nil
+}
+
+// remove the elements at the given indexes, which must be in ascending
+// order, in a single pass that compacts each node in place
+func (u *Unrolled[T]) removeIndexes(deleteList []uint32) {
+	if len(deleteList) == 0 {
+		return
+	}
+	var blank T
+	var i uint32 = 0
+	d := 0
+	var next *unrolledNode[T]
+	for node := u.head; node != nil && d < len(deleteList); node = next {
+		next = node.next
+		kept := 0
+		for k := 0; k < node.n; k++ {
+			// the same element may have been marked more than once
+			if d < len(deleteList) && deleteList[d] == i {
+				for d < len(deleteList) && deleteList[d] == i {
+					d++
+				}
+				u.Size--
+			} else {
+				node.items[kept] = node.items[k]
+				kept++
+			}
+			i++
+		}
+		for k := kept; k < node.n; k++ {
+			node.items[k] = blank
+		}
+		node.n = kept
+		if node.n == 0 {
+			u.unlinkNode(node)
+		}
+	}
+	// pack the nodes that are now less than half full
+	for node := u.head; node != nil; node = node.next {
+		for node.next != nil && shouldMerge(node, node.next) {
+			u.absorb(node, node.next)
+		}
+	}
+}
+
+// check the links agree with each other, with head and tail, and with
+// Size, and that no two neighbouring nodes should have been merged
+func (u *Unrolled[T]) Validate() error {
+	if (u.head == nil) != (u.tail == nil) {
+		return errors.New("only one of head and tail is nil")
+	}
+	var count uint32 = 0
+	var prev *unrolledNode[T]
+	i := 0
+	for node := u.head; node != nil; node = node.next {
+		if u.Size < count {
+			return fmt.Errorf("more than %d elements, or the links form a cycle", u.Size)
+		}
+		if node.prev != prev {
+			return fmt.Errorf("node %d has a broken prev link", i)
+		}
+		if node.n == 0 {
+			return fmt.Errorf("node %d is empty", i)
+		}
+		if prev != nil && shouldMerge(prev, node) {
+			return fmt.Errorf("nodes %d and %d hold %d and %d elements and should be merged", i-1, i, prev.n, node.n)
+		}
+		count += uint32(node.n)
+		prev = node
+		i++
+	}
+	if prev != u.tail {
+		return errors.New("last node is not the tail")
+	}
+	if count != u.Size {
+		return fmt.Errorf("size is %d but there are %d elements", u.Size, count)
+	}
+	return nil
+}
+
+func (u *Unrolled[T]) Array() []T {
+	ans := make([]T, 0, u.Size)
+	for node := u.head; node != nil; node = node.next {
+		ans = append(ans, node.items[:node.n]...)
+	}
+	return ans
+}
@@ -0,0 +1,221 @@
+package list_test
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnrolledRandom(t *testing.T) {
+	u := ll.CreateUnrolled[int]()
+	ref := make([]int, 0)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		v := rng.Intn(500)
+		idx := u.InsertSorted(v, scb)
+		ref = append(ref, v)
+		sort.Ints(ref)
+		assert.Equal(t, v, ref[idx])
+		assert.Nil(t, u.Validate())
+	}
+	assert.Equal(t, ref, u.Array())
+	assert.Equal(t, uint32(len(ref)), u.Size)
+	for _, i := range []uint32{0, 63, 64, 1500, 2999} {
+		v, ok := u.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, ref[i], v)
+	}
+	_, ok := u.Get(3000)
+	assert.False(t, ok)
+
+	// delete every value divisible by 3, marking some twice
+	assert.Nil(t, u.Iterate(func(obj int, index uint32, deleteNode func()) error {
+		if obj%3 == 0 {
+			deleteNode()
+			deleteNode()
+		}
+		return nil
+	}))
+	kept := make([]int, 0)
+	for _, v := range ref {
+		if v%3 != 0 {
+			kept = append(kept, v)
+		}
+	}
+	assert.Equal(t, kept, u.Array())
+	assert.Equal(t, uint32(len(kept)), u.Size)
+	assert.Nil(t, u.Validate())
+
+	for _, v := range kept {
+		x, ok := u.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, v, x)
+		assert.Nil(t, u.Validate())
+	}
+	_, ok = u.Pop()
+	assert.False(t, ok)
+	assert.Equal(t, uint32(0), u.Size)
+}
+
+func TestUnrolledEnds(t *testing.T) {
+	u := ll.CreateUnrolled[int]()
+	for i := 0; i < 200; i++ {
+		u.Append(i)
+		u.Prepend(-i - 1)
+	}
+	arr := u.Array()
+	assert.Equal(t, 400, len(arr))
+	for i := 1; i < len(arr); i++ {
+		assert.Equal(t, arr[i-1]+1, arr[i])
+	}
+	h, _ := u.Head()
+	tl, _ := u.Tail()
+	assert.Equal(t, -200, h)
+	assert.Equal(t, 199, tl)
+	assert.Nil(t, u.Validate())
+
+	// an error stops the iteration and nothing is deleted
+	stop := errors.New("stop")
+	assert.Equal(t, stop, u.Iterate(func(obj int, index uint32, deleteNode func()) error {
+		deleteNode()
+		if index == 10 {
+			return stop
+		}
+		return nil
+	}))
+	assert.Equal(t, uint32(400), u.Size)
+
+	// delete everything
+	assert.Nil(t, u.Iterate(func(obj int, index uint32, deleteNode func()) error {
+		deleteNode()
+		return nil
+	}))
+	assert.Equal(t, 0, len(u.Array()))
+	_, ok := u.Head()
+	assert.False(t, ok)
+	u.Append(5)
+	assert.Equal(t, []int{5}, u.Array())
+}
+
+func TestUnrolledSparseDelete(t *testing.T) {
+	u := ll.CreateUnrolled[int]()
+	for i := 0; i < 100*ll.UNROLLED_NODE_SIZE; i++ {
+		u.Append(i)
+	}
+	// keep one element out of every node
+	assert.Nil(t, u.Iterate(func(obj int, index uint32, deleteNode func()) error {
+		if obj%ll.UNROLLED_NODE_SIZE != 0 {
+			deleteNode()
+		}
+		return nil
+	}))
+	assert.Equal(t, uint32(100), u.Size)
+	assert.Nil(t, u.Validate(), "deletes left nearly empty nodes")
+	for i := 0; i < 100; i++ {
+		v, ok := u.Get(uint32(i))
+		assert.True(t, ok)
+		assert.Equal(t, i*ll.UNROLLED_NODE_SIZE, v)
+	}
+
+	// sorted inserts split nodes next to the small ones
+	for i := 0; i < 1000; i++ {
+		u.InsertSorted(i*7, scb)
+		assert.Nil(t, u.Validate())
+	}
+	for i := 0; i < 500; i++ {
+		u.Pop()
+		assert.Nil(t, u.Validate())
+	}
+	assert.Equal(t, uint32(600), u.Size)
+}
+
+type small struct {
+	a int64
+	b int32
+}
+
+const unrolledBenchN = 100_000
+
+func BenchmarkIterateGeneric(b *testing.B) {
+	q := ll.CreateGeneric[small]()
+	for j := 0; j < unrolledBenchN; j++ {
+		q.Append(small{a: int64(j)})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sum int64
+		for v := range q.Values() {
+			sum += v.a
+		}
+	}
+}
+
+func BenchmarkIterateUnrolled(b *testing.B) {
+	u := ll.CreateUnrolled[small]()
+	for j := 0; j < unrolledBenchN; j++ {
+		u.Append(small{a: int64(j)})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sum int64
+		for v := range u.Values() {
+			sum += v.a
+		}
+	}
+}
+
+// reports allocations and bytes per list of unrolledBenchN elements
+func BenchmarkAppendGeneric(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q := ll.CreateGeneric[small]()
+		for j := 0; j < unrolledBenchN; j++ {
+			q.Append(small{a: int64(j)})
+		}
+	}
+}
+
+func BenchmarkAppendUnrolled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		u := ll.CreateUnrolled[small]()
+		for j := 0; j < unrolledBenchN; j++ {
+			u.Append(small{a: int64(j)})
+		}
+	}
+}
+
+func BenchmarkInsertSortedUnrolled(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		u := ll.CreateUnrolled[int]()
+		for j := 0; j < 2000; j++ {
+			u.InsertSorted(rng.Int(), scb)
+		}
+	}
+}
+
+// iterate over what is left after deleting most of the elements
+func BenchmarkIterateUnrolledAfterDelete(b *testing.B) {
+	u := ll.CreateUnrolled[small]()
+	for j := 0; j < unrolledBenchN; j++ {
+		u.Append(small{a: int64(j)})
+	}
+	u.Iterate(func(obj small, index uint32, deleteNode func()) error {
+		if obj.a%ll.UNROLLED_NODE_SIZE != 0 {
+			deleteNode()
+		}
+		return nil
+	})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sum int64
+		for v := range u.Values() {
+			sum += v.a
+		}
+	}
+}
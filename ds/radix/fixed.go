@@ -0,0 +1,118 @@
+package radix
+
+import (
+	"iter"
+	"unsafe"
+)
+
+// FixedTree is a radix tree keyed by 32 byte arrays such as sgo.PublicKey
+// and sgo.Hash. Keys are viewed as strings in place rather than converted,
+// so Get, Delete, the walks and Insert on an existing key do not allocate.
+// Inserting a new key copies it once into the tree.
+type FixedTree[K ~[32]byte, T any] struct {
+	t *Tree[T]
+}
+
+// NewFixed returns an empty FixedTree
+func NewFixed[K ~[32]byte, T any]() *FixedTree[K, T] {
+	return &FixedTree[K, T]{t: New[T]()}
+}
+
+// view the key as a string without copying; the string must not outlive
+// the key or be retained by the tree
+func keyView[K ~[32]byte](key *K) string {
+	b := (*[32]byte)(unsafe.Pointer(key))
+	return unsafe.String(&b[0], len(b))
+}
+
+// view the prefix as a string without copying
+func prefixView(prefix []byte) string {
+	return unsafe.String(unsafe.SliceData(prefix), len(prefix))
+}
+
+func fixedKey[K ~[32]byte](s string) (key K) {
+	copy(key[:], s)
+	return
+}
+
+// Len is used to return the number of elements in the tree
+func (f *FixedTree[K, T]) Len() int {
+	return f.t.Len()
+}
+
+// Get is used to lookup a specific key, returning
+// the value and if it was found
+func (f *FixedTree[K, T]) Get(key K) (ans T, result bool) {
+	return f.t.Get(keyView(&key))
+}
+
+// Insert is used to add a new entry or update
+// an existing entry. Returns true if an existing record is updated.
+func (f *FixedTree[K, T]) Insert(key K, v T) (ans T, result bool) {
+	if leaf := f.t.root.getLeaf(keyView(&key)); leaf != nil {
+		ans = leaf.val
+		leaf.val = v
+		result = true
+		return
+	}
+	// the tree keeps the key, so it gets its own copy
+	return f.t.Insert(string(key[:]), v)
+}
+
+// Delete is used to delete a key, returning the previous
+// value and if it was deleted
+func (f *FixedTree[K, T]) Delete(key K) (ans T, result bool) {
+	return f.t.Delete(keyView(&key))
+}
+
+// DeletePrefix is used to delete the subtree under a prefix.
+// Returns how many keys were deleted
+func (f *FixedTree[K, T]) DeletePrefix(prefix []byte) int {
+	return f.t.DeletePrefix(prefixView(prefix))
+}
+
+// Minimum is used to return the minimum value in the tree
+func (f *FixedTree[K, T]) Minimum() (index K, ans T, result bool) {
+	s, ans, result := f.t.Minimum()
+	return fixedKey[K](s), ans, result
+}
+
+// Maximum is used to return the maximum value in the tree
+func (f *FixedTree[K, T]) Maximum() (index K, ans T, result bool) {
+	s, ans, result := f.t.Maximum()
+	return fixedKey[K](s), ans, result
+}
+
+// Walk is used to walk the tree in key order.
+// Return true from fn to stop the walk.
+func (f *FixedTree[K, T]) Walk(fn func(key K, v T) bool) {
+	f.t.Walk(func(s string, v T) bool {
+		return fn(fixedKey[K](s), v)
+	})
+}
+
+// WalkPrefix is used to walk the keys starting with prefix in key order.
+// Return true from fn to stop the walk.
+func (f *FixedTree[K, T]) WalkPrefix(prefix []byte, fn func(key K, v T) bool) {
+	f.t.WalkPrefix(prefixView(prefix), func(s string, v T) bool {
+		return fn(fixedKey[K](s), v)
+	})
+}
+
+// All yields every key and value in key order
+func (f *FixedTree[K, T]) All() iter.Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		f.Walk(func(key K, v T) bool {
+			return !yield(key, v)
+		})
+	}
+}
+
+// Prefix yields every key and value under prefix in key order
+func (f *FixedTree[K, T]) Prefix(prefix []byte) iter.Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		f.WalkPrefix(prefix, func(key K, v T) bool {
+			return !yield(key, v)
+		})
+	}
+}
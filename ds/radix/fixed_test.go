@@ -0,0 +1,128 @@
+package radix_test
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	sgo "github.com/SolmateDev/solana-go"
+	"github.com/solpipe/solpipe-util/ds/radix"
+)
+
+func randomKeys(n int) []sgo.PublicKey {
+	rng := rand.New(rand.NewSource(1))
+	ans := make([]sgo.PublicKey, n)
+	for i := range ans {
+		rng.Read(ans[i][:])
+		// share prefixes so that the tree has inner nodes
+		ans[i][0] = byte(i % 4)
+	}
+	return ans
+}
+
+func TestFixedTree(t *testing.T) {
+	keys := randomKeys(1000)
+	r := radix.NewFixed[sgo.PublicKey, int]()
+	for i, k := range keys {
+		if _, updated := r.Insert(k, i); updated {
+			t.Fatalf("duplicate key %d", i)
+		}
+	}
+	if r.Len() != len(keys) {
+		t.Fatalf("bad length: %v %v", r.Len(), len(keys))
+	}
+	for i, k := range keys {
+		v, ok := r.Get(k)
+		if !ok || v != i {
+			t.Fatalf("bad value for key %d: %v %v", i, v, ok)
+		}
+	}
+	old, updated := r.Insert(keys[0], -1)
+	if !updated || old != 0 {
+		t.Fatalf("bad update: %v %v", old, updated)
+	}
+
+	sorted := make([]sgo.PublicKey, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+	i := 0
+	for k := range r.All() {
+		if k != sorted[i] {
+			t.Fatalf("key %d out of order", i)
+		}
+		i++
+	}
+	min, _, _ := r.Minimum()
+	max, _, _ := r.Maximum()
+	if min != sorted[0] || max != sorted[len(sorted)-1] {
+		t.Fatal("bad minimum or maximum")
+	}
+
+	count := 0
+	for k := range r.Prefix([]byte{2}) {
+		if k[0] != 2 {
+			t.Fatalf("key outside prefix: %v", k)
+		}
+		count++
+	}
+	if count != len(keys)/4 {
+		t.Fatalf("bad prefix count: %v", count)
+	}
+	if n := r.DeletePrefix([]byte{2}); n != len(keys)/4 {
+		t.Fatalf("bad delete prefix count: %v", n)
+	}
+	if _, ok := r.Delete(keys[1]); !ok {
+		t.Fatal("missing key")
+	}
+	if _, ok := r.Get(keys[1]); ok {
+		t.Fatal("deleted key is present")
+	}
+	if r.Len() != len(keys)-len(keys)/4-1 {
+		t.Fatalf("bad length: %v", r.Len())
+	}
+}
+
+func TestFixedTreeAllocs(t *testing.T) {
+	keys := randomKeys(1000)
+	r := radix.NewFixed[sgo.PublicKey, int]()
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Get(keys[i%len(keys)])
+		r.Insert(keys[i%len(keys)], i)
+		i++
+	})
+	if allocs != 0 {
+		t.Fatalf("get and update allocated %v times", allocs)
+	}
+}
+
+func BenchmarkFixedGet(b *testing.B) {
+	keys := randomKeys(10000)
+	r := radix.NewFixed[sgo.PublicKey, int]()
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.Get(keys[n%len(keys)])
+	}
+}
+
+// the conversion FixedTree avoids
+func BenchmarkBase58Get(b *testing.B) {
+	keys := randomKeys(10000)
+	r := radix.New[int]()
+	for i, k := range keys {
+		r.Insert(k.String(), i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.Get(keys[n%len(keys)].String())
+	}
+}
@@ -0,0 +1,373 @@
+package radix
+
+import (
+	"iter"
+	"strings"
+)
+
+// ImmutableTree is a persistent radix tree. It is never modified once
+// created: changes are made in a Txn, and Commit returns a new tree that
+// shares every untouched node with the old one. Holding on to a tree is
+// therefore an O(1) snapshot, and any number of goroutines may read a tree
+// while a writer prepares the next one. Publish new versions to readers
+// through an atomic.Pointer or a channel.
+type ImmutableTree[T any] struct {
+	root *node[T]
+	size int
+}
+
+// NewImmutable returns an empty ImmutableTree
+func NewImmutable[T any]() *ImmutableTree[T] {
+	return &ImmutableTree[T]{root: &node[T]{}}
+}
+
+// Len is used to return the number of elements in the tree
+func (t *ImmutableTree[T]) Len() int {
+	return t.size
+}
+
+// Get is used to lookup a specific key, returning
+// the value and if it was found
+func (t *ImmutableTree[T]) Get(s string) (ans T, result bool) {
+	leaf := t.root.getLeaf(s)
+	if leaf == nil {
+		result = false
+		return
+	}
+	return leaf.val, true
+}
+
+// Minimum is used to return the minimum value in the tree
+func (t *ImmutableTree[T]) Minimum() (index string, ans T, result bool) {
+	if leaf := t.root.minimum(); leaf != nil {
+		return leaf.key, leaf.val, true
+	}
+	result = false
+	return
+}
+
+// Maximum is used to return the maximum value in the tree
+func (t *ImmutableTree[T]) Maximum() (index string, ans T, result bool) {
+	if leaf := t.root.maximum(); leaf != nil {
+		return leaf.key, leaf.val, true
+	}
+	result = false
+	return
+}
+
+// Walk is used to walk the tree in key order
+func (t *ImmutableTree[T]) Walk(fn WalkFn[T]) {
+	recursiveWalk(t.root, fn)
+}
+
+// WalkPrefix is used to walk the tree under a prefix
+func (t *ImmutableTree[T]) WalkPrefix(prefix string, fn WalkFn[T]) {
+	t.root.walkPrefix(prefix, fn)
+}
+
+// All yields every key and value in key order
+func (t *ImmutableTree[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		t.Walk(func(k string, v T) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Prefix yields every key and value under prefix in key order
+func (t *ImmutableTree[T]) Prefix(prefix string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		t.WalkPrefix(prefix, func(k string, v T) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Insert returns a new tree with the key set to v; see Txn.Insert
+func (t *ImmutableTree[T]) Insert(s string, v T) (*ImmutableTree[T], T, bool) {
+	txn := t.Txn()
+	ans, result := txn.Insert(s, v)
+	return txn.Commit(), ans, result
+}
+
+// Delete returns a new tree without the key; see Txn.Delete
+func (t *ImmutableTree[T]) Delete(s string) (*ImmutableTree[T], T, bool) {
+	txn := t.Txn()
+	ans, result := txn.Delete(s)
+	return txn.Commit(), ans, result
+}
+
+// Txn starts a batch of changes on top of this tree
+func (t *ImmutableTree[T]) Txn() *Txn[T] {
+	return &Txn[T]{
+		root:     t.root,
+		size:     t.size,
+		writable: make(map[*node[T]]struct{}),
+	}
+}
+
+// Txn batches changes to an ImmutableTree. A node is copied the first time
+// the transaction changes it and updated in place after that, so a batch of
+// writes under the same prefix copies the shared path only once. A Txn
+// must only be used by one goroutine.
+type Txn[T any] struct {
+	root *node[T]
+	size int
+	// nodes created by this transaction that no tree has seen yet
+	writable map[*node[T]]struct{}
+}
+
+// returns a node that is safe to change in place
+func (txn *Txn[T]) writeNode(n *node[T]) *node[T] {
+	if _, present := txn.writable[n]; present {
+		return n
+	}
+	nc := &node[T]{leaf: n.leaf, prefix: n.prefix}
+	if 0 < len(n.edges) {
+		nc.edges = make(edges[T], len(n.edges))
+		copy(nc.edges, n.edges)
+	}
+	txn.writable[nc] = struct{}{}
+	return nc
+}
+
+// returns a new node, owned by this transaction
+func (txn *Txn[T]) newNode(n *node[T]) *node[T] {
+	txn.writable[n] = struct{}{}
+	return n
+}
+
+// pull the only child of a writable node up into it
+func (txn *Txn[T]) mergeChild(n *node[T]) {
+	child := n.edges[0].node
+	n.prefix = n.prefix + child.prefix
+	n.leaf = child.leaf
+	n.edges = nil
+	if 0 < len(child.edges) {
+		// the child may be shared, so its edges are copied
+		n.edges = make(edges[T], len(child.edges))
+		copy(n.edges, child.edges)
+	}
+}
+
+// Len is used to return the number of elements in the transaction
+func (txn *Txn[T]) Len() int {
+	return txn.size
+}
+
+// Get is used to lookup a specific key, seeing the changes
+// made so far in the transaction
+func (txn *Txn[T]) Get(s string) (ans T, result bool) {
+	leaf := txn.root.getLeaf(s)
+	if leaf == nil {
+		result = false
+		return
+	}
+	return leaf.val, true
+}
+
+// Insert is used to add a new entry or update
+// an existing entry. Returns true if an existing record is updated.
+func (txn *Txn[T]) Insert(s string, v T) (ans T, result bool) {
+	txn.root, ans, result = txn.insert(txn.root, s, s, v)
+	if !result {
+		txn.size++
+	}
+	return
+}
+
+func (txn *Txn[T]) insert(n *node[T], key string, search string, v T) (*node[T], T, bool) {
+	var ans T
+	// leaves may be shared, so they are replaced rather than updated
+	leaf := &leafNode[T]{key: key, val: v}
+
+	// Handle key exhaution
+	if len(search) == 0 {
+		nc := txn.writeNode(n)
+		result := false
+		if n.isLeaf() {
+			ans = n.leaf.val
+			result = true
+		}
+		nc.leaf = leaf
+		return nc, ans, result
+	}
+
+	// No edge, create one
+	child := n.getEdge(search[0])
+	if child == nil {
+		nc := txn.writeNode(n)
+		nc.addEdge(edge[T]{
+			label: search[0],
+			node:  txn.newNode(&node[T]{leaf: leaf, prefix: search}),
+		})
+		return nc, ans, false
+	}
+
+	// The child covers the search key so far; continue below it
+	commonPrefix := longestPrefix(search, child.prefix)
+	if commonPrefix == len(child.prefix) {
+		newChild, ans, result := txn.insert(child, key, search[commonPrefix:], v)
+		if newChild == child {
+			return n, ans, result
+		}
+		nc := txn.writeNode(n)
+		nc.updateEdge(search[0], newChild)
+		return nc, ans, result
+	}
+
+	// Split the child
+	nc := txn.writeNode(n)
+	split := txn.newNode(&node[T]{prefix: search[:commonPrefix]})
+	nc.updateEdge(search[0], split)
+
+	// Restore the existing child below the split
+	modChild := txn.writeNode(child)
+	modChild.prefix = child.prefix[commonPrefix:]
+	split.addEdge(edge[T]{
+		label: modChild.prefix[0],
+		node:  modChild,
+	})
+
+	// If the new key is a subset, add to the split node
+	search = search[commonPrefix:]
+	if len(search) == 0 {
+		split.leaf = leaf
+		return nc, ans, false
+	}
+	split.addEdge(edge[T]{
+		label: search[0],
+		node:  txn.newNode(&node[T]{leaf: leaf, prefix: search}),
+	})
+	return nc, ans, false
+}
+
+// Delete is used to delete a key, returning the previous
+// value and if it was deleted
+func (txn *Txn[T]) Delete(s string) (ans T, result bool) {
+	newRoot, leaf := txn.delete(txn.root, s, true)
+	if leaf == nil {
+		result = false
+		return
+	}
+	txn.root = newRoot
+	txn.size--
+	return leaf.val, true
+}
+
+// delete returns the replacement for n, or nil if n is left empty, and the
+// deleted leaf; if the leaf is nil, nothing has changed
+func (txn *Txn[T]) delete(n *node[T], search string, isRoot bool) (*node[T], *leafNode[T]) {
+	// Check for key exhaution
+	if len(search) == 0 {
+		if !n.isLeaf() {
+			return nil, nil
+		}
+		leaf := n.leaf
+		if !isRoot && len(n.edges) == 0 {
+			return nil, leaf
+		}
+		nc := txn.writeNode(n)
+		nc.leaf = nil
+		if !isRoot && len(nc.edges) == 1 {
+			txn.mergeChild(nc)
+		}
+		return nc, leaf
+	}
+
+	// Look for an edge
+	label := search[0]
+	child := n.getEdge(label)
+	if child == nil || !strings.HasPrefix(search, child.prefix) {
+		return nil, nil
+	}
+	newChild, leaf := txn.delete(child, search[len(child.prefix):], false)
+	if leaf == nil {
+		return nil, nil
+	}
+
+	nc := txn.writeNode(n)
+	if newChild == nil {
+		nc.delEdge(label)
+		if !isRoot && len(nc.edges) == 1 && !nc.isLeaf() {
+			txn.mergeChild(nc)
+		}
+	} else {
+		nc.updateEdge(label, newChild)
+	}
+	return nc, leaf
+}
+
+// DeletePrefix is used to delete the subtree under a prefix.
+// Returns how many keys were deleted
+func (txn *Txn[T]) DeletePrefix(s string) int {
+	newRoot, count := txn.deletePrefix(txn.root, s, true)
+	if count == 0 {
+		return 0
+	}
+	if newRoot == nil {
+		newRoot = txn.newNode(&node[T]{})
+	}
+	txn.root = newRoot
+	txn.size -= count
+	return count
+}
+
+func countLeaves[T any](n *node[T]) int {
+	count := 0
+	recursiveWalk(n, func(s string, v T) bool {
+		count++
+		return false
+	})
+	return count
+}
+
+// deletePrefix returns the replacement for n, or nil if n is left empty,
+// and the number of keys deleted
+func (txn *Txn[T]) deletePrefix(n *node[T], prefix string, isRoot bool) (*node[T], int) {
+	// Check for key exhaustion
+	if len(prefix) == 0 {
+		return nil, countLeaves(n)
+	}
+
+	// Look for an edge
+	label := prefix[0]
+	child := n.getEdge(label)
+	if child == nil {
+		return n, 0
+	}
+	var newChild *node[T]
+	var count int
+	if strings.HasPrefix(child.prefix, prefix) {
+		// every key below the child starts with prefix
+		newChild, count = nil, countLeaves(child)
+	} else if strings.HasPrefix(prefix, child.prefix) {
+		newChild, count = txn.deletePrefix(child, prefix[len(child.prefix):], false)
+	}
+	if count == 0 {
+		return n, 0
+	}
+
+	nc := txn.writeNode(n)
+	if newChild == nil {
+		nc.delEdge(label)
+	} else {
+		nc.updateEdge(label, newChild)
+	}
+	if !isRoot && !nc.isLeaf() {
+		if len(nc.edges) == 0 {
+			return nil, count
+		} else if len(nc.edges) == 1 {
+			txn.mergeChild(nc)
+		}
+	}
+	return nc, count
+}
+
+// Commit returns the tree with the changes made so far. The transaction
+// can keep going afterwards; the committed tree is not affected.
+func (txn *Txn[T]) Commit() *ImmutableTree[T] {
+	// the nodes now belong to a tree, so further changes must copy them
+	txn.writable = make(map[*node[T]]struct{})
+	return &ImmutableTree[T]{root: txn.root, size: txn.size}
+}
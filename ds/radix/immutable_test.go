@@ -0,0 +1,192 @@
+package radix_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/radix"
+)
+
+func immutableToMap(t *radix.ImmutableTree[int]) map[string]int {
+	out := make(map[string]int)
+	t.Walk(func(k string, v int) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
+
+// compare against a map over random inserts, deletes and prefix deletes,
+// keeping every committed version to check it never changes afterwards
+func TestImmutableRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ref := make(map[string]int)
+	tree := radix.NewImmutable[int]()
+	versions := []*radix.ImmutableTree[int]{tree}
+	expected := []map[string]int{{}}
+
+	for round := 0; round < 50; round++ {
+		txn := tree.Txn()
+		for i := 0; i < 40; i++ {
+			// short keys over a small alphabet so that they share prefixes
+			k := strconv.FormatInt(int64(rng.Intn(300)), 3)
+			switch rng.Intn(10) {
+			case 0:
+				p := k[:1]
+				n := 0
+				for x := range ref {
+					if len(p) <= len(x) && x[:len(p)] == p {
+						delete(ref, x)
+						n++
+					}
+				}
+				if m := txn.DeletePrefix(p); m != n {
+					t.Fatalf("deleted %d keys under %s, expected %d", m, p, n)
+				}
+			case 1, 2, 3:
+				_, present := ref[k]
+				delete(ref, k)
+				if _, ok := txn.Delete(k); ok != present {
+					t.Fatalf("delete %s: %v, expected %v", k, ok, present)
+				}
+			default:
+				_, present := ref[k]
+				ref[k] = i
+				if _, updated := txn.Insert(k, i); updated != present {
+					t.Fatalf("insert %s: %v, expected %v", k, updated, present)
+				}
+			}
+			want, present := ref[k]
+			if v, ok := txn.Get(k); ok != present || v != want {
+				t.Fatalf("txn does not see its own change to %s", k)
+			}
+		}
+		tree = txn.Commit()
+		snapshot := make(map[string]int, len(ref))
+		for k, v := range ref {
+			snapshot[k] = v
+		}
+		versions = append(versions, tree)
+		expected = append(expected, snapshot)
+	}
+
+	for i, v := range versions {
+		if v.Len() != len(expected[i]) {
+			t.Fatalf("version %d: bad length %d, expected %d", i, v.Len(), len(expected[i]))
+		}
+		if !reflect.DeepEqual(immutableToMap(v), expected[i]) {
+			t.Fatalf("version %d changed after commit", i)
+		}
+	}
+
+	keys := make([]string, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	i := 0
+	for k := range tree.All() {
+		if k != keys[i] {
+			t.Fatalf("key %d out of order: %s %s", i, k, keys[i])
+		}
+		i++
+	}
+	if 0 < len(keys) {
+		min, _, _ := tree.Minimum()
+		max, _, _ := tree.Maximum()
+		if min != keys[0] || max != keys[len(keys)-1] {
+			t.Fatalf("bad minimum or maximum: %s %s", min, max)
+		}
+	}
+}
+
+func TestImmutableTxn(t *testing.T) {
+	a, _, _ := radix.NewImmutable[int]().Insert("foo", 1)
+	b, old, updated := a.Insert("foo", 2)
+	if !updated || old != 1 {
+		t.Fatalf("bad update: %v %v", old, updated)
+	}
+	if v, _ := a.Get("foo"); v != 1 {
+		t.Fatal("snapshot changed")
+	}
+	if v, _ := b.Get("foo"); v != 2 {
+		t.Fatal("update is missing")
+	}
+
+	// a transaction may keep going after a commit without changing it
+	txn := b.Txn()
+	txn.Insert("foobar", 3)
+	c := txn.Commit()
+	txn.Insert("food", 4)
+	txn.Delete("foo")
+	d := txn.Commit()
+	if !reflect.DeepEqual(immutableToMap(c), map[string]int{"foo": 2, "foobar": 3}) {
+		t.Fatalf("bad tree: %v", immutableToMap(c))
+	}
+	if !reflect.DeepEqual(immutableToMap(d), map[string]int{"foobar": 3, "food": 4}) {
+		t.Fatalf("bad tree: %v", immutableToMap(d))
+	}
+	keys := make([]string, 0)
+	for k := range d.Prefix("foo") {
+		keys = append(keys, k)
+	}
+	if !reflect.DeepEqual(keys, []string{"foobar", "food"}) {
+		t.Fatalf("bad prefix walk: %v", keys)
+	}
+	e, _, deleted := d.Delete("missing")
+	if deleted || e.Len() != 2 {
+		t.Fatal("deleted a missing key")
+	}
+}
+
+// run with go test -race; readers never lock
+func TestImmutableConcurrentReaders(t *testing.T) {
+	current := atomic.Pointer[radix.ImmutableTree[int]]{}
+	current.Store(radix.NewImmutable[int]())
+	N := 200
+	done := atomic.Bool{}
+	wg := &sync.WaitGroup{}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+				snapshot := current.Load()
+				// every version holds the keys 0..n-1 with value = key
+				n := snapshot.Len()
+				for i := 0; i < n; i++ {
+					v, ok := snapshot.Get(strconv.Itoa(i))
+					if !ok || v != i {
+						t.Errorf("version of length %d is missing %d", n, i)
+						return
+					}
+				}
+			}
+		}()
+	}
+	tree := current.Load()
+	for i := 0; i < N; i++ {
+		txn := tree.Txn()
+		txn.Insert(strconv.Itoa(i), i)
+		tree = txn.Commit()
+		current.Store(tree)
+	}
+	done.Store(true)
+	wg.Wait()
+}
+
+func BenchmarkImmutableTxnInsert(b *testing.B) {
+	tree := radix.NewImmutable[bool]()
+	for n := 0; n < b.N; n++ {
+		txn := tree.Txn()
+		for i := 0; i < 100; i++ {
+			txn.Insert(strconv.Itoa(n*100+i), true)
+		}
+		tree = txn.Commit()
+	}
+}
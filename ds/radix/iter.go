@@ -0,0 +1,21 @@
+package radix
+
+import "iter"
+
+// All yields every key and value in key order
+func (t *Tree[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		t.Walk(func(k string, v T) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Prefix yields every key and value under prefix in key order
+func (t *Tree[T]) Prefix(prefix string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		t.WalkPrefix(prefix, func(k string, v T) bool {
+			return !yield(k, v)
+		})
+	}
+}
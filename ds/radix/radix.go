@@ -361,17 +361,24 @@ func (n *node[T]) mergeChild() {
 // Get is used to lookup a specific key, returning
 // the value and if it was found
 func (t *Tree[T]) Get(s string) (ans T, result bool) {
-	n := t.root
+	leaf := t.root.getLeaf(s)
+	if leaf == nil {
+		result = false
+		return
+	}
+	ans = leaf.val
+	result = true
+	return
+}
+
+// getLeaf returns the leaf holding the key s under the
+// root n, or nil. s is not retained.
+func (n *node[T]) getLeaf(s string) *leafNode[T] {
 	search := s
 	for {
 		// Check for key exhaution
 		if len(search) == 0 {
-			if n.isLeaf() {
-				ans = n.leaf.val
-				result = true
-				return
-			}
-			break
+			return n.leaf
 		}
 
 		// Look for an edge
@@ -387,8 +394,7 @@ func (t *Tree[T]) Get(s string) (ans T, result bool) {
 			break
 		}
 	}
-	result = false
-	return
+	return nil
 }
 
 // LongestPrefix is like Get, but instead of an
@@ -434,42 +440,45 @@ func (t *Tree[T]) LongestPrefix(s string) (index string, ans interface{}, result
 
 // Minimum is used to return the minimum value in the tree
 func (t *Tree[T]) Minimum() (index string, ans T, result bool) {
-	n := t.root
+	if leaf := t.root.minimum(); leaf != nil {
+		return leaf.key, leaf.val, true
+	}
+	result = false
+	return
+}
+
+// minimum returns the smallest leaf below the root n, or nil
+func (n *node[T]) minimum() *leafNode[T] {
 	for {
 		if n.isLeaf() {
-			index = n.leaf.key
-			ans = n.leaf.val
-			result = true
-			return
+			return n.leaf
 		}
 		if len(n.edges) > 0 {
 			n = n.edges[0].node
 		} else {
-			break
+			return nil
 		}
 	}
-	result = false
-	return
 }
 
 // Maximum is used to return the maximum value in the tree
 func (t *Tree[T]) Maximum() (index string, ans T, result bool) {
-	n := t.root
+	if leaf := t.root.maximum(); leaf != nil {
+		return leaf.key, leaf.val, true
+	}
+	result = false
+	return
+}
+
+// maximum returns the largest leaf below the root n, or nil
+func (n *node[T]) maximum() *leafNode[T] {
 	for {
 		if num := len(n.edges); num > 0 {
 			n = n.edges[num-1].node
 			continue
 		}
-		if n.isLeaf() {
-			index = n.leaf.key
-			ans = n.leaf.val
-			result = true
-			return
-		}
-		break
+		return n.leaf
 	}
-	result = false
-	return
 }
 
 // Walk is used to walk the tree
@@ -479,7 +488,11 @@ func (t *Tree[T]) Walk(fn WalkFn[T]) {
 
 // WalkPrefix is used to walk the tree under a prefix
 func (t *Tree[T]) WalkPrefix(prefix string, fn WalkFn[T]) {
-	n := t.root
+	t.root.walkPrefix(prefix, fn)
+}
+
+// walkPrefix walks the keys under prefix below the root n
+func (n *node[T]) walkPrefix(prefix string, fn WalkFn[T]) {
 	search := prefix
 	for {
 		// Check for key exhaustion
@@ -0,0 +1,140 @@
+package ring
+
+import "errors"
+
+// Deque is a double ended queue backed by a ring buffer that doubles in
+// size when it is full. With shrinking enabled, the buffer halves once it
+// is a quarter full, but never drops below the initial size.
+// All operations are amortized O(1).
+type Deque[T any] struct {
+	list    []T
+	start   uint
+	length  uint
+	minSize uint
+	shrink  bool
+}
+
+func CreateDeque[T any](size uint64, shrink bool) (*Deque[T], error) {
+	if size == 0 {
+		return nil, errors.New("size is zero")
+	}
+	d := new(Deque[T])
+	d.list = make([]T, size)
+	d.start = 0
+	d.length = 0
+	d.minSize = uint(size)
+	d.shrink = shrink
+	return d, nil
+}
+
+// returns the current capacity of the buffer
+func (d *Deque[T]) Max() uint {
+	return uint(len(d.list))
+}
+
+// returns the number of elements in the deque
+func (d *Deque[T]) Length() uint {
+	return d.length
+}
+
+// copy the elements into a buffer of the given size, starting at slot 0
+func (d *Deque[T]) resize(size uint) {
+	list := make([]T, size)
+	if d.start+d.length <= d.Max() {
+		copy(list, d.list[d.start:d.start+d.length])
+	} else {
+		n := copy(list, d.list[d.start:])
+		copy(list[n:], d.list[:d.length-uint(n)])
+	}
+	d.list = list
+	d.start = 0
+}
+
+func (d *Deque[T]) grow() {
+	if d.length == d.Max() {
+		d.resize(2 * d.Max())
+	}
+}
+
+func (d *Deque[T]) maybeShrink() {
+	if d.shrink && d.minSize < d.Max() && d.length <= d.Max()/4 {
+		size := d.Max() / 2
+		if size < d.minSize {
+			size = d.minSize
+		}
+		d.resize(size)
+	}
+}
+
+// add an element to the end of the deque
+func (d *Deque[T]) PushBack(value T) {
+	d.grow()
+	d.list[(d.start+d.length)%d.Max()] = value
+	d.length++
+}
+
+// add an element to the front of the deque
+func (d *Deque[T]) PushFront(value T) {
+	d.grow()
+	d.start = (d.start + d.Max() - 1) % d.Max()
+	d.list[d.start] = value
+	d.length++
+}
+
+// remove and return the first element
+func (d *Deque[T]) PopFront() (value T, err error) {
+	if d.length == 0 {
+		err = errors.New("deque is empty")
+		return
+	}
+	var blank T
+	value = d.list[d.start]
+	d.list[d.start] = blank
+	d.start = (d.start + 1) % d.Max()
+	d.length--
+	d.maybeShrink()
+	return
+}
+
+// remove and return the last element
+func (d *Deque[T]) PopBack() (value T, err error) {
+	if d.length == 0 {
+		err = errors.New("deque is empty")
+		return
+	}
+	var blank T
+	k := (d.start + d.length - 1) % d.Max()
+	value = d.list[k]
+	d.list[k] = blank
+	d.length--
+	d.maybeShrink()
+	return
+}
+
+// retrieve the Ith element from the front
+func (d *Deque[T]) Get(i uint) (value T, err error) {
+	if i < d.length {
+		value = d.list[(d.start+i)%d.Max()]
+	} else {
+		err = errors.New("requested element does not lie in deque")
+	}
+	return
+}
+
+// replace the Ith element from the front
+func (d *Deque[T]) Set(i uint, value T) error {
+	if d.length <= i {
+		return errors.New("requested element does not lie in deque")
+	}
+	d.list[(d.start+i)%d.Max()] = value
+	return nil
+}
+
+// returns the elements from front to back
+func (d *Deque[T]) Array() []T {
+	ans := make([]T, d.length)
+	for i := uint(0); i < d.length; i++ {
+		ans[i] = d.list[(d.start+i)%d.Max()]
+	}
+	return ans
+}
@@ -0,0 +1,68 @@
+package ring_test
+
+import (
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDequeGrow(t *testing.T) {
+	d, err := ring.CreateDeque[int](2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// mix both ends so the contents wrap around before each resize
+	expected := make([]int, 0)
+	for i := 0; i < 20; i++ {
+		if i%3 == 0 {
+			d.PushFront(i)
+			expected = append([]int{i}, expected...)
+		} else {
+			d.PushBack(i)
+			expected = append(expected, i)
+		}
+	}
+	assert.Equal(t, uint(20), d.Length())
+	assert.Equal(t, uint(32), d.Max())
+	assert.Equal(t, expected, d.Array())
+
+	assert.Nil(t, d.Set(5, -5))
+	v, err := d.Get(5)
+	assert.Nil(t, err)
+	assert.Equal(t, -5, v)
+	assert.NotNil(t, d.Set(20, 0))
+	_, err = d.Get(20)
+	assert.NotNil(t, err)
+
+	v, err = d.PopFront()
+	assert.Nil(t, err)
+	assert.Equal(t, expected[0], v)
+	v, err = d.PopBack()
+	assert.Nil(t, err)
+	assert.Equal(t, expected[len(expected)-1], v)
+}
+
+func TestDequeShrink(t *testing.T) {
+	d, err := ring.CreateDeque[int](4, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 64; i++ {
+		d.PushBack(i)
+	}
+	assert.Equal(t, uint(64), d.Max())
+	for i := 0; i < 60; i++ {
+		v, err := d.PopFront()
+		assert.Nil(t, err)
+		assert.Equal(t, i, v)
+	}
+	assert.Equal(t, []int{60, 61, 62, 63}, d.Array())
+	assert.Less(t, d.Max(), uint(64), "buffer did not shrink")
+	for d.Length() > 0 {
+		d.PopBack()
+	}
+	assert.Equal(t, uint(4), d.Max(), "shrank below the initial size")
+	_, err = d.PopBack()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,303 @@
+package ring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrOverrun = errors.New("reader has been overrun by the writer")
+
+// seq of a slot while the writer is filling it
+const slotWriting uint64 = math.MaxUint64
+
+// The slots are allocated once and reused. seq says which event a slot
+// holds; readers count the goroutines copying the value out, and the writer
+// waits for them to finish before overwriting it.
+type fanoutSlot[T any] struct {
+	seq     atomic.Uint64
+	readers atomic.Int32
+	value   T
+}
+
+// Fanout is a single writer, multi reader ring buffer in the style of the
+// LMAX Disruptor. Every reader keeps its own sequence cursor into the
+// shared buffer, so an event is stored once no matter how many readers
+// consume it. The slots are allocated up front and publishing does not
+// allocate.
+//
+// A blocking reader holds back the writer until it has consumed an event.
+// A non-blocking reader is skipped; if the writer laps it, the reader gets
+// ErrOverrun and jumps forward to the oldest event still in the buffer.
+//
+// Publish and TryPublish must only be called from one goroutine at a time.
+type Fanout[T any] struct {
+	slots     []fanoutSlot[T]
+	published atomic.Uint64
+	readers   atomic.Pointer[[]*Reader[T]]
+	closed    atomic.Bool
+	waiting   atomic.Int32
+	mutex     *sync.Mutex
+	changeC   chan struct{}
+}
+
+func CreateFanout[T any](size uint64) (*Fanout[T], error) {
+	if size == 0 {
+		return nil, errors.New("size is zero")
+	}
+	f := new(Fanout[T])
+	f.slots = make([]fanoutSlot[T], size)
+	f.mutex = &sync.Mutex{}
+	f.changeC = make(chan struct{})
+	readers := make([]*Reader[T], 0)
+	f.readers.Store(&readers)
+	return f, nil
+}
+
+// returns the size of the buffer
+func (f *Fanout[T]) Max() uint64 {
+	return uint64(len(f.slots))
+}
+
+// returns the sequence number the next published event will get
+func (f *Fanout[T]) NextId() uint64 {
+	return f.published.Load()
+}
+
+// wake up everyone waiting on the writer or a reader to move
+func (f *Fanout[T]) notify() {
+	if f.waiting.Load() == 0 {
+		return
+	}
+	f.broadcast()
+}
+
+func (f *Fanout[T]) broadcast() {
+	f.mutex.Lock()
+	close(f.changeC)
+	f.changeC = make(chan struct{})
+	f.mutex.Unlock()
+}
+
+// block until ready returns true, the context ends or the fanout is closed
+func (f *Fanout[T]) wait(ctx context.Context, ready func() bool) error {
+	for {
+		if ready() {
+			return nil
+		}
+		f.mutex.Lock()
+		changeC := f.changeC
+		f.mutex.Unlock()
+		f.waiting.Add(1)
+		// check again now that the writer knows we are waiting
+		if ready() {
+			f.waiting.Add(-1)
+			return nil
+		}
+		if f.closed.Load() {
+			f.waiting.Add(-1)
+			return ErrClosed
+		}
+		select {
+		case <-ctx.Done():
+			f.waiting.Add(-1)
+			return ctx.Err()
+		case <-changeC:
+			f.waiting.Add(-1)
+		}
+	}
+}
+
+// returns the cursor of the slowest reader, blocking or not.
+// is_present is false when there are no readers.
+func (f *Fanout[T]) Slowest() (seq uint64, is_present bool) {
+	for _, r := range *f.readers.Load() {
+		c := r.cursor.Load()
+		if !is_present || c < seq {
+			seq = c
+			is_present = true
+		}
+	}
+	return
+}
+
+// returns true if publishing seq would overwrite an event a blocking
+// reader has not consumed yet
+func (f *Fanout[T]) gated(seq uint64) bool {
+	for _, r := range *f.readers.Load() {
+		if r.blocking && f.Max() <= seq-r.cursor.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fanout[T]) write(seq uint64, value T) {
+	s := &f.slots[seq%f.Max()]
+	// readers that come along from here on see that the old event is gone
+	s.seq.Store(slotWriting)
+	for s.readers.Load() != 0 {
+		// a lapped reader is still copying the old event out
+		runtime.Gosched()
+	}
+	s.value = value
+	s.seq.Store(seq)
+	f.published.Store(seq + 1)
+	f.notify()
+}
+
+// copy the event with sequence number seq out of its slot.
+// is_present is false if the slot has been reused for a later event.
+func (f *Fanout[T]) read(seq uint64) (value T, is_present bool) {
+	s := &f.slots[seq%f.Max()]
+	s.readers.Add(1)
+	if s.seq.Load() == seq {
+		value = s.value
+		is_present = true
+	}
+	s.readers.Add(-1)
+	return
+}
+
+// publish an event without waiting.
+// an error is returned if a blocking reader has fallen a full buffer behind.
+func (f *Fanout[T]) TryPublish(value T) (uint64, error) {
+	if f.closed.Load() {
+		return 0, ErrClosed
+	}
+	seq := f.published.Load()
+	if f.gated(seq) {
+		return 0, errors.New("buffer is full")
+	}
+	f.write(seq, value)
+	return seq, nil
+}
+
+// publish an event, waiting for blocking readers to make space
+func (f *Fanout[T]) Publish(ctx context.Context, value T) (uint64, error) {
+	if f.closed.Load() {
+		return 0, ErrClosed
+	}
+	seq := f.published.Load()
+	err := f.wait(ctx, func() bool { return !f.gated(seq) })
+	if err != nil {
+		return 0, err
+	}
+	f.write(seq, value)
+	return seq, nil
+}
+
+// stop publishing and wake up all waiters.
+// readers can still consume whatever is left in the buffer.
+func (f *Fanout[T]) Close() {
+	f.closed.Store(true)
+	f.broadcast()
+}
+
+// add a reader starting at the next event to be published.
+// a blocking reader must keep consuming or call Close, otherwise the
+// writer stalls.
+func (f *Fanout[T]) AddReader(blocking bool) *Reader[T] {
+	r := &Reader[T]{f: f, blocking: blocking}
+	f.mutex.Lock()
+	r.cursor.Store(f.published.Load())
+	old := *f.readers.Load()
+	list := make([]*Reader[T], len(old), len(old)+1)
+	copy(list, old)
+	list = append(list, r)
+	f.readers.Store(&list)
+	f.mutex.Unlock()
+	return r
+}
+
+func (f *Fanout[T]) removeReader(r *Reader[T]) {
+	f.mutex.Lock()
+	old := *f.readers.Load()
+	list := make([]*Reader[T], 0, len(old))
+	for _, x := range old {
+		if x != r {
+			list = append(list, x)
+		}
+	}
+	f.readers.Store(&list)
+	f.mutex.Unlock()
+	f.notify()
+}
+
+// Reader consumes events from a Fanout. A Reader must only be used by
+// one goroutine at a time.
+type Reader[T any] struct {
+	f        *Fanout[T]
+	cursor   atomic.Uint64
+	blocking bool
+}
+
+// returns the sequence number of the next event this reader will consume
+func (r *Reader[T]) Cursor() uint64 {
+	return r.cursor.Load()
+}
+
+// returns the number of published events this reader has not consumed
+func (r *Reader[T]) Lag() uint64 {
+	return r.f.published.Load() - r.cursor.Load()
+}
+
+// consume the next event without waiting.
+// is_present is false if the reader has caught up with the writer.
+func (r *Reader[T]) TryNext() (value T, seq uint64, is_present bool, err error) {
+	c := r.cursor.Load()
+	pub := r.f.published.Load()
+	if pub <= c {
+		if r.f.closed.Load() {
+			err = ErrClosed
+		}
+		return
+	}
+	value, is_present = r.f.read(c)
+	if !is_present {
+		// the writer lapped us; skip to the oldest event still around
+		oldest := r.f.published.Load() - r.f.Max()
+		if oldest <= c {
+			// the writer is still filling the slot we wanted
+			oldest = c + 1
+		}
+		r.cursor.Store(oldest)
+		r.f.notify()
+		err = fmt.Errorf("%w: skipped %d events", ErrOverrun, oldest-c)
+		return
+	}
+	seq = c
+	r.cursor.Store(c + 1)
+	r.f.notify()
+	return
+}
+
+// consume the next event, waiting until the writer publishes one
+func (r *Reader[T]) Next(ctx context.Context) (value T, seq uint64, err error) {
+	var is_present bool
+	for {
+		value, seq, is_present, err = r.TryNext()
+		if err != nil || is_present {
+			return
+		}
+		err = r.f.wait(ctx, func() bool {
+			return r.cursor.Load() < r.f.published.Load()
+		})
+		if err != nil {
+			if errors.Is(err, ErrClosed) {
+				// drain whatever the writer published before closing
+				continue
+			}
+			return
+		}
+	}
+}
+
+// detach the reader so it no longer holds back the writer
+func (r *Reader[T]) Close() {
+	r.f.removeReader(r)
+}
@@ -0,0 +1,134 @@
+package ring_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanoutBlockingReaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+	f, err := ring.CreateFanout[int](8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	N := 5000
+	readerCount := 4
+	wg := &sync.WaitGroup{}
+	for j := 0; j < readerCount; j++ {
+		r := f.AddReader(true)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				v, seq, err := r.Next(ctx)
+				if errors.Is(err, ring.ErrClosed) {
+					assert.Equal(t, N, i, "missing events")
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				assert.Equal(t, i, v)
+				assert.Equal(t, uint64(i), seq)
+			}
+		}()
+	}
+
+	for i := 0; i < N; i++ {
+		if _, err = f.Publish(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+	wg.Wait()
+}
+
+func TestFanoutOverrun(t *testing.T) {
+	f, err := ring.CreateFanout[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow := f.AddReader(false)
+	for i := 0; i < 10; i++ {
+		_, err = f.TryPublish(i)
+		assert.Nil(t, err, "non-blocking reader held back the writer")
+	}
+	seq, ok := f.Slowest()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), seq)
+	assert.Equal(t, uint64(10), slow.Lag())
+
+	_, _, _, err = slow.TryNext()
+	assert.ErrorIs(t, err, ring.ErrOverrun)
+	v, seq, ok, err := slow.TryNext()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 6, v)
+	assert.Equal(t, uint64(6), seq)
+
+	gate := f.AddReader(true)
+	for i := 0; i < 4; i++ {
+		_, err = f.TryPublish(i)
+		assert.Nil(t, err)
+	}
+	_, err = f.TryPublish(4)
+	assert.NotNil(t, err, "blocking reader was overwritten")
+	gate.Close()
+	_, err = f.TryPublish(4)
+	assert.Nil(t, err)
+}
+
+func TestFanoutNoAllocs(t *testing.T) {
+	f, err := ring.CreateFanout[int](8)
+	assert.Nil(t, err)
+	r := f.AddReader(true)
+	allocs := testing.AllocsPerRun(100, func() {
+		f.TryPublish(1)
+		r.TryNext()
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+// run with go test -race; a non-blocking reader is lapped over and over
+// while it copies events out of the slots the writer is reusing
+func TestFanoutLappedReader(t *testing.T) {
+	f, err := ring.CreateFanout[[4]int](4)
+	assert.Nil(t, err)
+	r := f.AddReader(false)
+	N := 20000
+	doneC := make(chan struct{})
+	go func() {
+		defer close(doneC)
+		for {
+			v, seq, ok, err := r.TryNext()
+			if errors.Is(err, ring.ErrClosed) {
+				return
+			}
+			if ok {
+				// the value must never be torn between two events
+				for _, x := range v {
+					if x != int(seq) {
+						t.Errorf("torn event %d: %v", seq, v)
+						return
+					}
+				}
+			}
+		}
+	}()
+	for i := 0; i < N; i++ {
+		x := i
+		f.TryPublish([4]int{x, x, x, x})
+	}
+	f.Close()
+	<-doneC
+}
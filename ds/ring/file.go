@@ -0,0 +1,435 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// File layout
+//
+//	[header A][header B][slot 0][slot 1]...[slot capacity-1]
+//
+// The two header copies are written alternately so that a torn header
+// write always leaves the previous header intact. Each slot holds
+//
+//	[id uint64][length uint32][payload recordSize bytes][crc32 uint32]
+//
+// where the checksum covers id, length and payload.
+const (
+	fileMagic        = "SOLRING1"
+	fileHeaderSize   = 64
+	fileSlotOverhead = 8 + 4 + 4
+)
+
+var fileByteOrder = binary.LittleEndian
+
+// ErrCorruptRecord is returned when a record fails its checksum or does not
+// hold the id it should, which is what a torn write leaves behind
+var ErrCorruptRecord = errors.New("record is corrupt")
+
+// storage is the backing store of a FileRing, either a plain file or a
+// memory mapped file.
+type storage interface {
+	io.ReaderAt
+	io.WriterAt
+	Sync() error
+	Close() error
+}
+
+type fileStorage struct {
+	f *os.File
+}
+
+func (s fileStorage) ReadAt(b []byte, off int64) (int, error) {
+	return s.f.ReadAt(b, off)
+}
+
+func (s fileStorage) WriteAt(b []byte, off int64) (int, error) {
+	return s.f.WriteAt(b, off)
+}
+
+func (s fileStorage) Sync() error {
+	return s.f.Sync()
+}
+
+func (s fileStorage) Close() error {
+	return s.f.Close()
+}
+
+type fileHeader struct {
+	version    uint64
+	recordSize uint32
+	capacity   uint64
+	start      uint64
+	length     uint64
+	lastId     uint64
+}
+
+func (h *fileHeader) encode() []byte {
+	b := make([]byte, fileHeaderSize)
+	copy(b[0:8], fileMagic)
+	fileByteOrder.PutUint64(b[8:16], h.version)
+	fileByteOrder.PutUint32(b[16:20], h.recordSize)
+	fileByteOrder.PutUint64(b[20:28], h.capacity)
+	fileByteOrder.PutUint64(b[28:36], h.start)
+	fileByteOrder.PutUint64(b[36:44], h.length)
+	fileByteOrder.PutUint64(b[44:52], h.lastId)
+	fileByteOrder.PutUint32(b[52:56], crc32.ChecksumIEEE(b[0:52]))
+	return b
+}
+
+func decodeFileHeader(b []byte) (h *fileHeader, err error) {
+	if !bytes.Equal(b[0:8], []byte(fileMagic)) {
+		err = errors.New("bad magic")
+		return
+	}
+	if crc32.ChecksumIEEE(b[0:52]) != fileByteOrder.Uint32(b[52:56]) {
+		err = errors.New("header checksum mismatch")
+		return
+	}
+	h = &fileHeader{
+		version:    fileByteOrder.Uint64(b[8:16]),
+		recordSize: fileByteOrder.Uint32(b[16:20]),
+		capacity:   fileByteOrder.Uint64(b[20:28]),
+		start:      fileByteOrder.Uint64(b[28:36]),
+		length:     fileByteOrder.Uint64(b[36:44]),
+		lastId:     fileByteOrder.Uint64(b[44:52]),
+	}
+	return
+}
+
+// FileRing is a ring buffer of fixed size records kept in a preallocated
+// file so that it survives restarts. Record ids follow the same monotonic
+// sequence as Ring. Records carry a checksum; on open, records that were
+// torn by a crash are dropped and records written just before a crash
+// whose header update was lost are recovered.
+//
+// FileRing is not safe for concurrent use.
+type FileRing struct {
+	s      storage
+	header fileHeader
+}
+
+// open the ring stored at filePath, creating the file if it does not exist.
+// recordSize and capacity must match the values the file was created with.
+// If useMmap is set, the file is memory mapped instead of read and written
+// with system calls.
+func OpenFile(filePath string, recordSize uint32, capacity uint64, useMmap bool) (*FileRing, error) {
+	if recordSize == 0 {
+		return nil, errors.New("record size is zero")
+	}
+	if capacity == 0 {
+		return nil, errors.New("size is zero")
+	}
+	f, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fileSize := int64(2*fileHeaderSize) + int64(capacity)*int64(uint64(recordSize)+fileSlotOverhead)
+	isNew := info.Size() == 0
+	if isNew {
+		err = f.Truncate(fileSize)
+	} else if info.Size() != fileSize {
+		err = fmt.Errorf("file size %d does not match record size and capacity", info.Size())
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	fr := new(FileRing)
+	if useMmap {
+		fr.s, err = mmapStorage(f, fileSize)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	} else {
+		fr.s = fileStorage{f: f}
+	}
+
+	if isNew {
+		fr.header = fileHeader{recordSize: recordSize, capacity: capacity}
+		err = fr.writeHeader()
+	} else {
+		err = fr.load(recordSize, capacity)
+	}
+	if err != nil {
+		fr.s.Close()
+		return nil, err
+	}
+	return fr, nil
+}
+
+// read both header copies and keep the newest valid one, then check
+// the records it points to
+func (fr *FileRing) load(recordSize uint32, capacity uint64) error {
+	var best *fileHeader
+	blank := true
+	b := make([]byte, fileHeaderSize)
+	for i := int64(0); i < 2; i++ {
+		if _, err := fr.s.ReadAt(b, i*fileHeaderSize); err != nil {
+			return err
+		}
+		if !bytes.Equal(b, make([]byte, fileHeaderSize)) {
+			blank = false
+		}
+		h, err := decodeFileHeader(b)
+		if err != nil {
+			continue
+		}
+		if best == nil || best.version < h.version {
+			best = h
+		}
+	}
+	if best == nil && blank {
+		// the file was extended but the first header never made it to
+		// disk, so the ring is still empty
+		fr.header = fileHeader{recordSize: recordSize, capacity: capacity}
+		return fr.writeHeader()
+	}
+	if best == nil {
+		return errors.New("no valid header")
+	}
+	if best.recordSize != recordSize || best.capacity != capacity {
+		return errors.New("record size or capacity does not match file")
+	}
+	fr.header = *best
+
+	// drop torn records at the tail
+	for 0 < fr.header.length {
+		last := fr.header.lastId - 1
+		if _, err := fr.readSlot(fr.slotOf(fr.header.length-1), last); err == nil {
+			break
+		}
+		fr.header.length--
+		fr.header.lastId--
+	}
+	// pick up records that made it to disk before their header did
+	for {
+		k := (fr.header.start + fr.header.length) % fr.header.capacity
+		if _, err := fr.readSlot(k, fr.header.lastId); err != nil {
+			break
+		}
+		if fr.header.length == fr.header.capacity {
+			fr.header.start = (fr.header.start + 1) % fr.header.capacity
+		} else {
+			fr.header.length++
+		}
+		fr.header.lastId++
+	}
+	// on a full ring, OverwriteAppend writes over the head; if that write
+	// was torn, the head record is gone
+	for 0 < fr.header.length {
+		if _, err := fr.readSlot(fr.header.start, fr.FirstId()); err == nil {
+			break
+		}
+		fr.dropHead()
+	}
+	return fr.writeHeader()
+}
+
+func (fr *FileRing) writeHeader() error {
+	fr.header.version++
+	off := int64(fr.header.version%2) * fileHeaderSize
+	_, err := fr.s.WriteAt(fr.header.encode(), off)
+	return err
+}
+
+func (fr *FileRing) slotSize() int64 {
+	return int64(fr.header.recordSize) + fileSlotOverhead
+}
+
+func (fr *FileRing) slotOffset(k uint64) int64 {
+	return 2*fileHeaderSize + int64(k)*fr.slotSize()
+}
+
+// map the Ith element onto its slot
+func (fr *FileRing) slotOf(i uint64) uint64 {
+	return (fr.header.start + i) % fr.header.capacity
+}
+
+// read the record in slot k, checking it has the expected id
+func (fr *FileRing) readSlot(k uint64, id uint64) ([]byte, error) {
+	b := make([]byte, fr.slotSize())
+	if _, err := fr.s.ReadAt(b, fr.slotOffset(k)); err != nil {
+		return nil, err
+	}
+	n := len(b) - 4
+	if crc32.ChecksumIEEE(b[:n]) != fileByteOrder.Uint32(b[n:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorruptRecord)
+	}
+	if fileByteOrder.Uint64(b[0:8]) != id {
+		return nil, fmt.Errorf("%w: id mismatch", ErrCorruptRecord)
+	}
+	size := fileByteOrder.Uint32(b[8:12])
+	if fr.header.recordSize < size {
+		return nil, fmt.Errorf("%w: bad length", ErrCorruptRecord)
+	}
+	return b[12 : 12+size], nil
+}
+
+func (fr *FileRing) writeSlot(k uint64, id uint64, data []byte) error {
+	b := make([]byte, fr.slotSize())
+	fileByteOrder.PutUint64(b[0:8], id)
+	fileByteOrder.PutUint32(b[8:12], uint32(len(data)))
+	copy(b[12:], data)
+	n := len(b) - 4
+	fileByteOrder.PutUint32(b[n:], crc32.ChecksumIEEE(b[:n]))
+	_, err := fr.s.WriteAt(b, fr.slotOffset(k))
+	return err
+}
+
+// returns the size of the buffer
+func (fr *FileRing) Max() uint64 {
+	return fr.header.capacity
+}
+
+// returns the number of records in the buffer
+func (fr *FileRing) Length() uint64 {
+	return fr.header.length
+}
+
+// returns the maximum size of a record in bytes
+func (fr *FileRing) RecordSize() uint32 {
+	return fr.header.recordSize
+}
+
+// returns the id of the first record in the buffer
+func (fr *FileRing) FirstId() uint64 {
+	return fr.header.lastId - fr.header.length
+}
+
+// returns the id that will be assigned to the next appended record
+func (fr *FileRing) NextId() uint64 {
+	return fr.header.lastId
+}
+
+// add a record to the end of the buffer.
+// data may be shorter than the record size, but not longer.
+func (fr *FileRing) Append(data []byte) (uint64, error) {
+	if fr.header.length == fr.header.capacity {
+		return 0, errors.New("buffer is full")
+	}
+	return fr.write(data)
+}
+
+// add a record to the end of the buffer, overwriting the first record
+// if the buffer is full
+func (fr *FileRing) OverwriteAppend(data []byte) (uint64, error) {
+	return fr.write(data)
+}
+
+func (fr *FileRing) write(data []byte) (uint64, error) {
+	if uint32(len(data)) > fr.header.recordSize {
+		return 0, errors.New("record is too large")
+	}
+	id := fr.header.lastId
+	err := fr.writeSlot(fr.slotOf(fr.header.length), id, data)
+	if err != nil {
+		return 0, err
+	}
+	if fr.header.length == fr.header.capacity {
+		fr.header.start = (fr.header.start + 1) % fr.header.capacity
+	} else {
+		fr.header.length++
+	}
+	fr.header.lastId++
+	if err = fr.writeHeader(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (fr *FileRing) dropHead() {
+	fr.header.start = (fr.header.start + 1) % fr.header.capacity
+	fr.header.length--
+}
+
+// remove and return the first record from the buffer.
+// Corrupt records at the head are dropped along the way.
+func (fr *FileRing) Pop() ([]byte, error) {
+	if fr.header.length == 0 {
+		return nil, errors.New("buffer is empty")
+	}
+	for 0 < fr.header.length {
+		data, err := fr.readSlot(fr.header.start, fr.FirstId())
+		if err != nil && !errors.Is(err, ErrCorruptRecord) {
+			return nil, err
+		}
+		fr.dropHead()
+		if err != nil {
+			continue
+		}
+		if err = fr.writeHeader(); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	// every record left was corrupt
+	if err := fr.writeHeader(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("buffer is empty")
+}
+
+// retrieve the Ith record from the buffer
+func (fr *FileRing) Get(i uint64) ([]byte, error) {
+	if fr.header.length <= i {
+		return nil, errors.New("requested element does not lie in buffer")
+	}
+	return fr.readSlot(fr.slotOf(i), fr.FirstId()+i)
+}
+
+// retrieve record by id
+func (fr *FileRing) GetById(id uint64) ([]byte, error) {
+	if id < fr.FirstId() {
+		return nil, errors.New("id has been removed from buffer")
+	} else if fr.header.lastId <= id {
+		return nil, errors.New("id has not been assigned yet")
+	}
+	return fr.Get(id - fr.FirstId())
+}
+
+// retrieve all records with ids starting from id up to the end of the buffer
+func (fr *FileRing) Since(id uint64) ([][]byte, error) {
+	if fr.header.lastId < id {
+		return nil, errors.New("id has not been assigned yet")
+	}
+	if id < fr.FirstId() {
+		return nil, errors.New("id has been removed from buffer")
+	}
+	ans := make([][]byte, 0, fr.header.lastId-id)
+	for ; id < fr.header.lastId; id++ {
+		data, err := fr.GetById(id)
+		if err != nil {
+			return nil, err
+		}
+		ans = append(ans, data)
+	}
+	return ans, nil
+}
+
+// flush all writes to disk
+func (fr *FileRing) Sync() error {
+	return fr.s.Sync()
+}
+
+// flush and close the file
+func (fr *FileRing) Close() error {
+	err := fr.s.Sync()
+	if err2 := fr.s.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
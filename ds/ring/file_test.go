@@ -0,0 +1,228 @@
+package ring_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRecordSize uint32 = 16
+
+func record(i int) []byte {
+	return []byte(fmt.Sprintf("record-%d", i))
+}
+
+func TestFileRingReopen(t *testing.T) {
+	for _, useMmap := range []bool{false, true} {
+		fp := filepath.Join(t.TempDir(), "ring.dat")
+		fr, err := ring.OpenFile(fp, testRecordSize, 4, useMmap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 6; i++ {
+			id, err := fr.OverwriteAppend(record(i))
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(i), id)
+		}
+		_, err = fr.Append(record(6))
+		assert.NotNil(t, err, "appended to a full ring")
+		_, err = fr.OverwriteAppend(make([]byte, testRecordSize+1))
+		assert.NotNil(t, err, "accepted an oversized record")
+		data, err := fr.Pop()
+		assert.Nil(t, err)
+		assert.Equal(t, record(2), data)
+		assert.Nil(t, fr.Close())
+
+		_, err = ring.OpenFile(fp, testRecordSize, 5, useMmap)
+		assert.NotNil(t, err, "opened with the wrong capacity")
+
+		fr, err = ring.OpenFile(fp, testRecordSize, 4, useMmap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, uint64(3), fr.Length())
+		assert.Equal(t, uint64(3), fr.FirstId())
+		assert.Equal(t, uint64(6), fr.NextId())
+		list, err := fr.Since(3)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{record(3), record(4), record(5)}, list)
+		_, err = fr.GetById(2)
+		assert.NotNil(t, err)
+		assert.Nil(t, fr.Close())
+	}
+}
+
+func TestFileRingTornWrite(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "ring.dat")
+	fr, err := ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err = fr.Append(record(i))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, fr.Close())
+
+	// flip a byte in the payload of the last record (slot 2)
+	f, err := os.OpenFile(fp, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotSize := int64(testRecordSize) + 16
+	_, err = f.WriteAt([]byte{0xff}, 128+2*slotSize+12)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	fr, err = ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), fr.Length(), "torn record was not dropped")
+	assert.Equal(t, uint64(2), fr.NextId())
+	assert.Nil(t, fr.Close())
+}
+
+func TestFileRingLostHeader(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "ring.dat")
+	fr, err := ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fr.Append(record(0))
+	assert.Nil(t, err)
+	assert.Nil(t, fr.Close())
+
+	f, err := os.OpenFile(fp, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := make([]byte, 128)
+	_, err = f.ReadAt(headers, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	fr, err = ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fr.Append(record(1))
+	assert.Nil(t, err)
+	assert.Nil(t, fr.Close())
+
+	// simulate a crash between writing the record and the header
+	f, err = os.OpenFile(fp, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteAt(headers, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	fr, err = ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), fr.Length(), "record was not recovered")
+	data, err := fr.GetById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, record(1), data)
+	assert.Nil(t, fr.Close())
+}
+
+// corrupt the payload of the record in slot k
+func corruptSlot(t *testing.T, fp string, k int64) {
+	f, err := os.OpenFile(fp, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotSize := int64(testRecordSize) + 16
+	_, err = f.WriteAt([]byte{0xff}, 128+k*slotSize+12)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+}
+
+func TestFileRingTornHead(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "ring.dat")
+	fr, err := ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		_, err = fr.Append(record(i))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, fr.Close())
+
+	// an OverwriteAppend on the full ring was torn while writing slot 0,
+	// which held the head record
+	corruptSlot(t, fp, 0)
+	fr, err = ring.OpenFile(fp, testRecordSize, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(3), fr.Length(), "torn head was not dropped")
+	assert.Equal(t, uint64(1), fr.FirstId())
+	list, err := fr.Since(fr.FirstId())
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{record(1), record(2), record(3)}, list)
+
+	// a record that goes bad while the ring is open is skipped by Pop
+	corruptSlot(t, fp, 1)
+	_, err = fr.Get(0)
+	assert.ErrorIs(t, err, ring.ErrCorruptRecord)
+	data, err := fr.Pop()
+	assert.Nil(t, err)
+	assert.Equal(t, record(2), data)
+	assert.Equal(t, uint64(1), fr.Length())
+	assert.Nil(t, fr.Close())
+}
+
+func TestFileRingNoHeader(t *testing.T) {
+	for _, useMmap := range []bool{false, true} {
+		// the process stopped after the new file was extended but before
+		// the first header was written
+		fp := filepath.Join(t.TempDir(), "ring.dat")
+		f, err := os.Create(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, f.Truncate(128+4*(int64(testRecordSize)+16)))
+		assert.Nil(t, f.Close())
+
+		fr, err := ring.OpenFile(fp, testRecordSize, 4, useMmap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, uint64(0), fr.Length())
+		_, err = fr.Append(record(0))
+		assert.Nil(t, err)
+		assert.Nil(t, fr.Close())
+
+		fr, err = ring.OpenFile(fp, testRecordSize, 4, useMmap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := fr.Pop()
+		assert.Nil(t, err)
+		assert.Equal(t, record(0), data)
+		assert.Nil(t, fr.Close())
+	}
+
+	// a header that is not blank but fails its checksum is still an error
+	fp := filepath.Join(t.TempDir(), "ring.dat")
+	f, err := os.Create(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, f.Truncate(128+4*(int64(testRecordSize)+16)))
+	_, err = f.WriteAt([]byte("SOLRING1"), 0)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	_, err = ring.OpenFile(fp, testRecordSize, 4, false)
+	assert.NotNil(t, err, "opened a file with a bad header")
+}
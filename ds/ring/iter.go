@@ -0,0 +1,27 @@
+package ring
+
+import "iter"
+
+// All yields the id and value of every element from oldest to newest.
+// The buffer must not be modified while the loop runs.
+func (r *Ring[T]) All() iter.Seq2[uint64, T] {
+	return func(yield func(uint64, T) bool) {
+		for i := uint(0); i < r.length; i++ {
+			n := r.list[(r.start+i)%r.Max()]
+			if !yield(n.id, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields every element from oldest to newest
+func (r *Ring[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range r.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package ring_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPSCStress(t *testing.T) {
+	q, err := ring.CreateSPSC[int](100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(128), q.Max())
+
+	N := 200000
+	go func() {
+		batch := make([]int, 0, 7)
+		for i := 0; i < N; {
+			if i%2 == 0 {
+				if q.TryEnqueue(i) {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+				continue
+			}
+			batch = batch[:0]
+			for j := i; j < N && len(batch) < cap(batch); j++ {
+				batch = append(batch, j)
+			}
+			n := q.TryEnqueueBatch(batch)
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+	}()
+
+	out := make([]int, 5)
+	for i := 0; i < N; {
+		if i%3 == 0 {
+			v, ok := q.TryDequeue()
+			if !ok {
+				runtime.Gosched()
+				continue
+			}
+			if v != i {
+				t.Fatalf("out of order: %d != %d", v, i)
+			}
+			i++
+			continue
+		}
+		n := q.TryDequeueBatch(out)
+		for j := 0; j < n; j++ {
+			if out[j] != i {
+				t.Fatalf("out of order: %d != %d", out[j], i)
+			}
+			i++
+		}
+		if n == 0 {
+			runtime.Gosched()
+		}
+	}
+	_, ok := q.TryDequeue()
+	assert.False(t, ok)
+}
+
+func TestMPMCBatch(t *testing.T) {
+	q, err := ring.CreateMPMC[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, q.TryEnqueueBatch(nil))
+	assert.Equal(t, 3, q.TryEnqueueBatch([]int{1, 2, 3}))
+	// only one cell is left
+	assert.Equal(t, 1, q.TryEnqueueBatch([]int{4, 5}))
+	assert.Equal(t, 0, q.TryEnqueueBatch([]int{6}))
+
+	out := []int{-1, -1, -1, -1, -1, -1}
+	assert.Equal(t, 4, q.TryDequeueBatch(out))
+	assert.Equal(t, []int{1, 2, 3, 4, -1, -1}, out, "wrote past the dequeued elements")
+	out[0] = -1
+	assert.Equal(t, 0, q.TryDequeueBatch(out[:1]))
+	assert.Equal(t, -1, out[0], "a failed dequeue wrote to the list")
+
+	// wrap around the end of the buffer
+	assert.Equal(t, 2, q.TryEnqueueBatch([]int{7, 8}))
+	assert.Equal(t, 2, q.TryDequeueBatch(out[:2]))
+	assert.Equal(t, []int{7, 8}, out[:2])
+}
+
+func TestMPMCStress(t *testing.T) {
+	q, err := ring.CreateMPMC[int](64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	producers := 4
+	consumers := 4
+	perProducer := 20000
+
+	wg := &sync.WaitGroup{}
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			if p%2 == 0 {
+				for i := 0; i < perProducer; i++ {
+					for !q.TryEnqueue(p*perProducer + i) {
+						runtime.Gosched()
+					}
+				}
+				return
+			}
+			// the odd producers send in batches
+			batch := make([]int, 0, 5)
+			for i := 0; i < perProducer; {
+				batch = batch[:0]
+				for j := i; j < perProducer && len(batch) < cap(batch); j++ {
+					batch = append(batch, p*perProducer+j)
+				}
+				n := q.TryEnqueueBatch(batch)
+				if n == 0 {
+					runtime.Gosched()
+				}
+				i += n
+			}
+		}(p)
+	}
+
+	resultC := make(chan []int, consumers)
+	var remaining sync.WaitGroup
+	remaining.Add(producers * perProducer)
+	doneC := make(chan struct{})
+	go func() {
+		remaining.Wait()
+		close(doneC)
+	}()
+	for c := 0; c < consumers; c++ {
+		go func() {
+			got := make([]int, 0)
+			buf := make([]int, 3)
+			for {
+				n := q.TryDequeueBatch(buf)
+				for j := 0; j < n; j++ {
+					got = append(got, buf[j])
+					remaining.Done()
+				}
+				if n == 0 {
+					select {
+					case <-doneC:
+						resultC <- got
+						return
+					default:
+						runtime.Gosched()
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make([]bool, producers*perProducer)
+	for c := 0; c < consumers; c++ {
+		got := <-resultC
+		// each consumer sees a producer's values in the order they were sent
+		last := make(map[int]int)
+		for _, v := range got {
+			assert.False(t, seen[v], "duplicate value %d", v)
+			seen[v] = true
+			p := v / perProducer
+			if prev, ok := last[p]; ok && v <= prev {
+				t.Fatalf("producer %d out of order: %d after %d", p, v, prev)
+			}
+			last[p] = v
+		}
+	}
+	for v, ok := range seen {
+		if !ok {
+			t.Fatalf("missing value %d", v)
+		}
+	}
+}
+
+const benchQueueSize = 1024
+
+// one producer, one consumer, spinning on the non-blocking calls
+func benchPair(b *testing.B, enqueue func(int) bool, dequeue func() bool) {
+	doneC := make(chan struct{})
+	go func() {
+		for i := 0; i < b.N; i++ {
+			for !dequeue() {
+				runtime.Gosched()
+			}
+		}
+		close(doneC)
+	}()
+	for i := 0; i < b.N; i++ {
+		for !enqueue(i) {
+			runtime.Gosched()
+		}
+	}
+	<-doneC
+}
+
+func BenchmarkSPSC(b *testing.B) {
+	q, _ := ring.CreateSPSC[int](benchQueueSize)
+	benchPair(b, q.TryEnqueue, func() bool {
+		_, ok := q.TryDequeue()
+		return ok
+	})
+}
+
+func BenchmarkMPMC(b *testing.B) {
+	q, _ := ring.CreateMPMC[int](benchQueueSize)
+	benchPair(b, q.TryEnqueue, func() bool {
+		_, ok := q.TryDequeue()
+		return ok
+	})
+}
+
+func BenchmarkChannel(b *testing.B) {
+	c := make(chan int, benchQueueSize)
+	benchPair(b, func(v int) bool {
+		select {
+		case c <- v:
+			return true
+		default:
+			return false
+		}
+	}, func() bool {
+		select {
+		case <-c:
+			return true
+		default:
+			return false
+		}
+	})
+}
+
+func BenchmarkMutexRing(b *testing.B) {
+	r, _ := ring.Create[int](benchQueueSize)
+	mutex := &sync.Mutex{}
+	benchPair(b, func(v int) bool {
+		mutex.Lock()
+		_, err := r.Append(v)
+		mutex.Unlock()
+		return err == nil
+	}, func() bool {
+		mutex.Lock()
+		_, err := r.Pop()
+		mutex.Unlock()
+		return err == nil
+	})
+}
+
+// several producers and consumers hammering the same queue
+func BenchmarkMPMCParallel(b *testing.B) {
+	q, _ := ring.CreateMPMC[int](benchQueueSize)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if !q.TryEnqueue(1) {
+				q.TryDequeue()
+			}
+			q.TryDequeue()
+		}
+	})
+}
+
+func BenchmarkMutexRingParallel(b *testing.B) {
+	r, _ := ring.Create[int](benchQueueSize)
+	mutex := &sync.Mutex{}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mutex.Lock()
+			if _, err := r.Append(1); err != nil {
+				r.Pop()
+			}
+			r.Pop()
+			mutex.Unlock()
+		}
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package ring
+
+import (
+	"errors"
+	"os"
+)
+
+func mmapStorage(f *os.File, size int64) (storage, error) {
+	return nil, errors.New("memory mapping is not supported on this platform")
+}
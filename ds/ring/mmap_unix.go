@@ -0,0 +1,54 @@
+//go:build unix
+
+package ring
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+type mmapFile struct {
+	f    *os.File
+	data []byte
+}
+
+func mmapStorage(f *os.File, size int64) (storage, error) {
+	data, err := unix.Mmap(int(f.Fd()), 0, int(size), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return &mmapFile{f: f, data: data}, nil
+}
+
+func (m *mmapFile) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 || int64(len(m.data)) < off {
+		return 0, errors.New("offset out of range")
+	}
+	n := copy(b, m.data[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *mmapFile) WriteAt(b []byte, off int64) (int, error) {
+	if off < 0 || int64(len(m.data)) < off+int64(len(b)) {
+		return 0, errors.New("offset out of range")
+	}
+	return copy(m.data[off:], b), nil
+}
+
+func (m *mmapFile) Sync() error {
+	return unix.Msync(m.data, unix.MS_SYNC)
+}
+
+func (m *mmapFile) Close() error {
+	err := unix.Munmap(m.data)
+	if err2 := m.f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+package ring
+
+import "sync/atomic"
+
+type mpmcCell[T any] struct {
+	seq   atomic.Uint64
+	value T
+}
+
+// MPMC is a lock-free bounded queue for any number of producers and
+// consumers, after Dmitry Vyukov's bounded MPMC queue. Every cell carries
+// a sequence number that tells producers and consumers whose turn it is,
+// so each operation is a single CAS on the shared position in the common
+// case. The capacity is rounded up to a power of two.
+type MPMC[T any] struct {
+	list []mpmcCell[T]
+	mask uint64
+	_    cacheLinePad
+	// next position to write
+	enqueuePos atomic.Uint64
+	_          cacheLinePad
+	// next position to read
+	dequeuePos atomic.Uint64
+	_          cacheLinePad
+}
+
+func CreateMPMC[T any](size uint64) (*MPMC[T], error) {
+	n, err := powerOfTwo(size)
+	if err != nil {
+		return nil, err
+	}
+	q := new(MPMC[T])
+	q.list = make([]mpmcCell[T], n)
+	for i := uint64(0); i < n; i++ {
+		q.list[i].seq.Store(i)
+	}
+	q.mask = n - 1
+	return q, nil
+}
+
+// returns the size of the buffer
+func (q *MPMC[T]) Max() uint64 {
+	return uint64(len(q.list))
+}
+
+// returns the approximate number of elements in the queue
+func (q *MPMC[T]) Length() uint64 {
+	head := q.dequeuePos.Load()
+	tail := q.enqueuePos.Load()
+	if tail < head {
+		return 0
+	}
+	return tail - head
+}
+
+// add an element; returns false if the queue is full
+func (q *MPMC[T]) TryEnqueue(value T) bool {
+	pos := q.enqueuePos.Load()
+	for {
+		cell := &q.list[pos&q.mask]
+		seq := cell.seq.Load()
+		dif := int64(seq - pos)
+		if dif == 0 {
+			if q.enqueuePos.CompareAndSwap(pos, pos+1) {
+				cell.value = value
+				cell.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.enqueuePos.Load()
+		} else if dif < 0 {
+			// the consumer has not freed this cell yet
+			return false
+		} else {
+			pos = q.enqueuePos.Load()
+		}
+	}
+}
+
+// add elements from list until the queue is full; returns how many were added.
+// The cells are reserved with a single CAS and then filled in order.
+func (q *MPMC[T]) TryEnqueueBatch(list []T) int {
+	if len(list) == 0 {
+		return 0
+	}
+	pos := q.enqueuePos.Load()
+	for {
+		// count the free cells from pos on
+		n := 0
+		for n < len(list) && n <= int(q.mask) {
+			if q.list[(pos+uint64(n))&q.mask].seq.Load() != pos+uint64(n) {
+				break
+			}
+			n++
+		}
+		if n == 0 {
+			dif := int64(q.list[pos&q.mask].seq.Load() - pos)
+			if dif < 0 {
+				// the consumer has not freed this cell yet
+				return 0
+			}
+			pos = q.enqueuePos.Load()
+			continue
+		}
+		if !q.enqueuePos.CompareAndSwap(pos, pos+uint64(n)) {
+			pos = q.enqueuePos.Load()
+			continue
+		}
+		for i := 0; i < n; i++ {
+			p := pos + uint64(i)
+			cell := &q.list[p&q.mask]
+			cell.value = list[i]
+			cell.seq.Store(p + 1)
+		}
+		return n
+	}
+}
+
+// remove the first element; is_present is false if the queue is empty
+func (q *MPMC[T]) TryDequeue() (value T, is_present bool) {
+	pos := q.dequeuePos.Load()
+	for {
+		cell := &q.list[pos&q.mask]
+		seq := cell.seq.Load()
+		dif := int64(seq - (pos + 1))
+		if dif == 0 {
+			if q.dequeuePos.CompareAndSwap(pos, pos+1) {
+				var blank T
+				value = cell.value
+				cell.value = blank
+				cell.seq.Store(pos + q.mask + 1)
+				is_present = true
+				return
+			}
+			pos = q.dequeuePos.Load()
+		} else if dif < 0 {
+			// no producer has filled this cell yet
+			return
+		} else {
+			pos = q.dequeuePos.Load()
+		}
+	}
+}
+
+// fill list with elements until the queue is empty; returns how many were
+// copied. The cells are claimed with a single CAS; list is only written up
+// to the returned count.
+func (q *MPMC[T]) TryDequeueBatch(list []T) int {
+	if len(list) == 0 {
+		return 0
+	}
+	pos := q.dequeuePos.Load()
+	for {
+		// count the filled cells from pos on
+		n := 0
+		for n < len(list) && n <= int(q.mask) {
+			if q.list[(pos+uint64(n))&q.mask].seq.Load() != pos+uint64(n)+1 {
+				break
+			}
+			n++
+		}
+		if n == 0 {
+			dif := int64(q.list[pos&q.mask].seq.Load() - (pos + 1))
+			if dif < 0 {
+				// no producer has filled this cell yet
+				return 0
+			}
+			pos = q.dequeuePos.Load()
+			continue
+		}
+		if !q.dequeuePos.CompareAndSwap(pos, pos+uint64(n)) {
+			pos = q.dequeuePos.Load()
+			continue
+		}
+		var blank T
+		for i := 0; i < n; i++ {
+			p := pos + uint64(i)
+			cell := &q.list[p&q.mask]
+			list[i] = cell.value
+			cell.value = blank
+			cell.seq.Store(p + q.mask + 1)
+		}
+		return n
+	}
+}
@@ -4,14 +4,17 @@ import "errors"
 
 type node[T any] struct {
 	value T
-	id    uint
+	id    uint64
 }
 
+// Ring is a fixed size circular buffer. Every appended element is assigned
+// an id from a monotonic sequence starting at 0. The ids of the elements
+// in the buffer are always contiguous, so an id maps directly onto a slot.
 type Ring[T any] struct {
 	list   []*node[T]
 	start  uint
 	length uint
-	lastId uint
+	lastId uint64
 }
 
 func Create[T any](size uint64) (*Ring[T], error) {
@@ -37,10 +40,21 @@ func (r *Ring[T]) Length() uint {
 	return r.length
 }
 
+// returns the id of the first element in the buffer.
+// if the buffer is empty, this is the id the next element will get.
+func (r *Ring[T]) FirstId() uint64 {
+	return r.lastId - uint64(r.length)
+}
+
+// returns the id that will be assigned to the next appended element
+func (r *Ring[T]) NextId() uint64 {
+	return r.lastId
+}
+
 // append an element to the end of the ring buffer.
 // if the buffer has reached max size, then pop the first element off.
-func (r *Ring[T]) OverwriteAppend(value T) uint {
-	if r.Length() == r.Max()-1 {
+func (r *Ring[T]) OverwriteAppend(value T) uint64 {
+	if r.Length() == r.Max() {
 		r.Pop()
 	}
 	id, err := r.Append(value)
@@ -52,8 +66,8 @@ func (r *Ring[T]) OverwriteAppend(value T) uint {
 }
 
 // add an element to the end of the buffer
-func (r *Ring[T]) Append(value T) (uint, error) {
-	if r.Length() == r.Max()-1 {
+func (r *Ring[T]) Append(value T) (uint64, error) {
+	if r.Length() == r.Max() {
 		return 0, errors.New("buffer is full")
 	}
 	i := (r.start + r.length) % r.Max()
@@ -71,6 +85,7 @@ func (r *Ring[T]) Pop() (value T, err error) {
 		return
 	} else {
 		n := r.list[r.start]
+		r.list[r.start] = nil
 		r.start = (r.start + 1) % r.Max()
 		r.length = r.length - 1
 		value = n.value
@@ -89,19 +104,90 @@ func (r *Ring[T]) Get(i uint) (value T, err error) {
 	return
 }
 
+// map an id onto the slot holding it
+func (r *Ring[T]) slot(id uint64) (k uint, err error) {
+	if id < r.FirstId() {
+		err = errors.New("id has been removed from buffer")
+		return
+	} else if r.lastId <= id {
+		err = errors.New("id has not been assigned yet")
+		return
+	}
+	k = (r.start + uint(id-r.FirstId())) % r.Max()
+	if r.list[k].id != id {
+		// we should never end up here
+		panic("ring slot does not match id")
+	}
+	return
+}
+
 // retrieve element by id
-func (r *Ring[T]) GetById(id uint) (value T, err error) {
-	i := id % r.Max()
-	if i < r.Length() {
-		k := (r.start + i) % r.Max()
-		if r.list[k].id == id {
-			value = r.list[k].value
+func (r *Ring[T]) GetById(id uint64) (value T, err error) {
+	k, err := r.slot(id)
+	if err != nil {
+		return
+	}
+	value = r.list[k].value
+	return
+}
+
+// retrieve the elements with ids in [fromId, toId)
+func (r *Ring[T]) GetRange(fromId uint64, toId uint64) ([]T, error) {
+	if toId < fromId {
+		return nil, errors.New("range is inverted")
+	}
+	if fromId == toId {
+		return []T{}, nil
+	}
+	k, err := r.slot(fromId)
+	if err != nil {
+		return nil, err
+	}
+	if r.lastId < toId {
+		return nil, errors.New("id has not been assigned yet")
+	}
+	ans := make([]T, toId-fromId)
+	for i := 0; i < len(ans); i++ {
+		ans[i] = r.list[k].value
+		k = (k + 1) % r.Max()
+	}
+	return ans, nil
+}
+
+// retrieve all elements with ids starting from id up to the end of the buffer.
+// Pass NextId() from the previous call as the cursor to catch up.
+// An error is returned if elements after the cursor have already been removed.
+func (r *Ring[T]) Since(id uint64) ([]T, error) {
+	if r.lastId < id {
+		return nil, errors.New("id has not been assigned yet")
+	}
+	return r.GetRange(id, r.lastId)
+}
+
+// returns the smallest index i for which pred is true, or Length() if there
+// is none. Like sort.Search, pred must be false for some prefix of the
+// buffer and true for the rest, which holds when the elements were appended
+// in increasing key order.
+func (r *Ring[T]) SearchFirst(pred func(T) bool) uint {
+	lo, hi := uint(0), r.length
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if pred(r.list[(r.start+mid)%r.Max()].value) {
+			hi = mid
 		} else {
-			err = errors.New("id not present")
+			lo = mid + 1
 		}
-	} else {
-		err = errors.New("requested element does not lie in buffer")
 	}
+	return lo
+}
 
-	return
+// returns the index of the first element that is not less than key,
+// or Length() if every element is less. compare follows the same
+// convention as list.CompareCallback: if < -> -1; = -> 0; > -> 1
+//
+// To read everything from key onwards, pass FirstId()+LowerBound(...) to Since.
+func (r *Ring[T]) LowerBound(key T, compare func(a T, b T) int) uint {
+	return r.SearchFirst(func(x T) bool {
+		return 0 <= compare(x, key)
+	})
 }
@@ -0,0 +1,139 @@
+package ring_test
+
+import (
+	"testing"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingFull(t *testing.T) {
+	r, err := ring.Create[int](3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err = r.Append(i)
+		assert.Nil(t, err, "failed to fill every slot")
+	}
+	_, err = r.Append(3)
+	assert.NotNil(t, err, "appended to a full ring")
+	assert.Equal(t, uint(3), r.Length())
+}
+
+func TestRingWraparound(t *testing.T) {
+	r, err := ring.Create[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each id is equal to the value stored under it
+	for i := 0; i < 11; i++ {
+		id := r.OverwriteAppend(i)
+		assert.Equal(t, uint64(i), id, "ids are not sequential")
+	}
+	assert.Equal(t, uint64(7), r.FirstId())
+	assert.Equal(t, uint64(11), r.NextId())
+
+	for id := uint64(7); id < 11; id++ {
+		v, err := r.GetById(id)
+		assert.Nil(t, err, "valid id reported missing")
+		assert.Equal(t, int(id), v)
+	}
+	_, err = r.GetById(6)
+	assert.NotNil(t, err, "overwritten id still present")
+	_, err = r.GetById(11)
+	assert.NotNil(t, err, "unassigned id present")
+
+	// pops shift the start but not the ids
+	v, err := r.Pop()
+	assert.Nil(t, err)
+	assert.Equal(t, 7, v)
+	v, err = r.GetById(9)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, v)
+	v, err = r.Get(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, v)
+}
+
+func TestRingRange(t *testing.T) {
+	r, err := ring.Create[int](5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		r.OverwriteAppend(i)
+	}
+
+	list, err := r.GetRange(4, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5, 6}, list)
+
+	_, err = r.GetRange(2, 5)
+	assert.NotNil(t, err, "range starts before buffer")
+	_, err = r.GetRange(5, 9)
+	assert.NotNil(t, err, "range ends after buffer")
+
+	cursor := r.FirstId()
+	list, err = r.Since(cursor)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3, 4, 5, 6, 7}, list)
+	cursor = r.NextId()
+
+	r.OverwriteAppend(8)
+	r.OverwriteAppend(9)
+	list, err = r.Since(cursor)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{8, 9}, list)
+
+	list, err = r.Since(r.NextId())
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+
+	_, err = r.Since(1)
+	assert.NotNil(t, err, "cursor fell behind")
+}
+
+type slotEvent struct {
+	slot uint64
+}
+
+func compareSlot(a slotEvent, b slotEvent) int {
+	if a.slot < b.slot {
+		return -1
+	} else if a.slot == b.slot {
+		return 0
+	} else {
+		return 1
+	}
+}
+
+func TestRingSearch(t *testing.T) {
+	r, err := ring.Create[slotEvent](6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := r.LowerBound(slotEvent{slot: 1}, compareSlot)
+	assert.Equal(t, uint(0), i, "empty ring")
+
+	// wrap around so the logical order differs from the slot order
+	for slot := uint64(10); slot < 30; slot += 2 {
+		r.OverwriteAppend(slotEvent{slot: slot})
+	}
+	// ring holds slots 18, 20, 22, 24, 26, 28
+	for _, x := range []struct {
+		slot  uint64
+		index uint
+	}{{0, 0}, {18, 0}, {19, 1}, {20, 1}, {27, 5}, {28, 5}, {29, 6}} {
+		i = r.LowerBound(slotEvent{slot: x.slot}, compareSlot)
+		assert.Equal(t, x.index, i, "slot %d", x.slot)
+	}
+
+	i = r.SearchFirst(func(e slotEvent) bool { return 23 < e.slot })
+	assert.Equal(t, uint(3), i)
+
+	list, err := r.Since(r.FirstId() + uint64(r.LowerBound(slotEvent{slot: 23}, compareSlot)))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(list))
+	assert.Equal(t, uint64(24), list[0].slot)
+}
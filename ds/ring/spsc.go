@@ -0,0 +1,143 @@
+package ring
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// keep hot atomics on separate cache lines
+type cacheLinePad [64]byte
+
+// round size up to the next power of two so slots can be found with a mask
+func powerOfTwo(size uint64) (uint64, error) {
+	if size == 0 {
+		return 0, errors.New("size is zero")
+	}
+	if 1<<63 < size {
+		return 0, errors.New("size is too large")
+	}
+	n := uint64(1)
+	for n < size {
+		n <<= 1
+	}
+	return n, nil
+}
+
+// SPSC is a lock-free bounded queue for exactly one producer goroutine
+// and one consumer goroutine. The capacity is rounded up to a power of two.
+type SPSC[T any] struct {
+	list []T
+	mask uint64
+	_    cacheLinePad
+	// next slot to read; written by the consumer
+	head atomic.Uint64
+	// producer's copy of head, so it rarely touches the consumer's line
+	cachedHead uint64
+	_          cacheLinePad
+	// next slot to write; written by the producer
+	tail atomic.Uint64
+	// consumer's copy of tail
+	cachedTail uint64
+	_          cacheLinePad
+}
+
+func CreateSPSC[T any](size uint64) (*SPSC[T], error) {
+	n, err := powerOfTwo(size)
+	if err != nil {
+		return nil, err
+	}
+	q := new(SPSC[T])
+	q.list = make([]T, n)
+	q.mask = n - 1
+	return q, nil
+}
+
+// returns the size of the buffer
+func (q *SPSC[T]) Max() uint64 {
+	return uint64(len(q.list))
+}
+
+// returns the number of elements in the queue; only exact when called
+// by the producer or the consumer while the other side is idle
+func (q *SPSC[T]) Length() uint64 {
+	return q.tail.Load() - q.head.Load()
+}
+
+// add an element; returns false if the queue is full.
+// must only be called by the producer.
+func (q *SPSC[T]) TryEnqueue(value T) bool {
+	tail := q.tail.Load()
+	if tail-q.cachedHead == q.Max() {
+		q.cachedHead = q.head.Load()
+		if tail-q.cachedHead == q.Max() {
+			return false
+		}
+	}
+	q.list[tail&q.mask] = value
+	q.tail.Store(tail + 1)
+	return true
+}
+
+// add as many elements from list as fit; returns how many were added.
+// must only be called by the producer.
+func (q *SPSC[T]) TryEnqueueBatch(list []T) int {
+	tail := q.tail.Load()
+	free := q.Max() - (tail - q.cachedHead)
+	if free < uint64(len(list)) {
+		q.cachedHead = q.head.Load()
+		free = q.Max() - (tail - q.cachedHead)
+	}
+	n := uint64(len(list))
+	if free < n {
+		n = free
+	}
+	for i := uint64(0); i < n; i++ {
+		q.list[(tail+i)&q.mask] = list[i]
+	}
+	if 0 < n {
+		q.tail.Store(tail + n)
+	}
+	return int(n)
+}
+
+// remove the first element; is_present is false if the queue is empty.
+// must only be called by the consumer.
+func (q *SPSC[T]) TryDequeue() (value T, is_present bool) {
+	head := q.head.Load()
+	if head == q.cachedTail {
+		q.cachedTail = q.tail.Load()
+		if head == q.cachedTail {
+			return
+		}
+	}
+	var blank T
+	k := head & q.mask
+	value = q.list[k]
+	q.list[k] = blank
+	q.head.Store(head + 1)
+	is_present = true
+	return
+}
+
+// fill list with as many elements as are available; returns how many
+// were copied. must only be called by the consumer.
+func (q *SPSC[T]) TryDequeueBatch(list []T) int {
+	head := q.head.Load()
+	if q.cachedTail-head < uint64(len(list)) {
+		q.cachedTail = q.tail.Load()
+	}
+	n := q.cachedTail - head
+	if uint64(len(list)) < n {
+		n = uint64(len(list))
+	}
+	var blank T
+	for i := uint64(0); i < n; i++ {
+		k := (head + i) & q.mask
+		list[i] = q.list[k]
+		q.list[k] = blank
+	}
+	if 0 < n {
+		q.head.Store(head + n)
+	}
+	return int(n)
+}
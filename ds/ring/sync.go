@@ -0,0 +1,147 @@
+package ring
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/solpipe/solpipe-util/ds/internal/signal"
+)
+
+var ErrClosed = errors.New("ring is closed")
+
+// SyncRing wraps Ring with a mutex so that it can be shared between goroutines.
+// Push and Pop block until there is space or data, the context ends, or the
+// ring is closed.
+type SyncRing[T any] struct {
+	mutex  *sync.Mutex
+	r      *Ring[T]
+	closed bool
+	change signal.Signal
+}
+
+func CreateSync[T any](size uint64) (*SyncRing[T], error) {
+	r, err := Create[T](size)
+	if err != nil {
+		return nil, err
+	}
+	s := new(SyncRing[T])
+	s.mutex = &sync.Mutex{}
+	s.r = r
+	s.closed = false
+	return s, nil
+}
+
+// returns the size of the buffer
+func (s *SyncRing[T]) Max() uint {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.r.Max()
+}
+
+// returns the number of elements in the buffer
+func (s *SyncRing[T]) Length() uint {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.r.Length()
+}
+
+// add an element to the end of the buffer without waiting.
+// an error is returned if the buffer is full or closed.
+func (s *SyncRing[T]) TryPush(value T) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	id, err := s.r.Append(value)
+	if err != nil {
+		return 0, err
+	}
+	s.change.Broadcast()
+	return id, nil
+}
+
+// add an element to the end of the buffer, waiting until there is space.
+func (s *SyncRing[T]) Push(ctx context.Context, value T) (uint64, error) {
+	doneC := ctx.Done()
+	for {
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			return 0, ErrClosed
+		}
+		id, err := s.r.Append(value)
+		if err == nil {
+			s.change.Broadcast()
+			s.mutex.Unlock()
+			return id, nil
+		}
+		changeC := s.change.Wait()
+		s.mutex.Unlock()
+
+		select {
+		case <-doneC:
+			return 0, ctx.Err()
+		case <-changeC:
+		}
+	}
+}
+
+// remove and return the first element without waiting.
+// elements pushed before Close can still be popped.
+func (s *SyncRing[T]) TryPop() (value T, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.r.Length() == 0 && s.closed {
+		err = ErrClosed
+		return
+	}
+	value, err = s.r.Pop()
+	if err != nil {
+		return
+	}
+	s.change.Broadcast()
+	return
+}
+
+// remove and return the first element, waiting until one is available.
+// once the ring is closed and drained, ErrClosed is returned.
+func (s *SyncRing[T]) Pop(ctx context.Context) (value T, err error) {
+	doneC := ctx.Done()
+	for {
+		s.mutex.Lock()
+		if 0 < s.r.Length() {
+			value, err = s.r.Pop()
+			s.change.Broadcast()
+			s.mutex.Unlock()
+			return
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			err = ErrClosed
+			return
+		}
+		changeC := s.change.Wait()
+		s.mutex.Unlock()
+
+		select {
+		case <-doneC:
+			err = ctx.Err()
+			return
+		case <-changeC:
+		}
+	}
+}
+
+// close the ring and wake up all waiters.
+// Push fails from here on; Pop drains what is left.
+func (s *SyncRing[T]) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.change.Broadcast()
+}
@@ -0,0 +1,113 @@
+package ring_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRingBlocking(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+	r, err := ring.CreateSync[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	N := 1000
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < N; i++ {
+			if _, err := r.Push(ctx, i); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		r.Close()
+	}()
+
+	for i := 0; ; i++ {
+		v, err := r.Pop(ctx)
+		if errors.Is(err, ring.ErrClosed) {
+			assert.Equal(t, N, i, "missing elements")
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, i, v, "out of order")
+	}
+	wg.Wait()
+}
+
+func TestSyncRingContext(t *testing.T) {
+	r, err := ring.CreateSync[int](2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = r.Pop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = r.TryPop()
+	assert.NotNil(t, err, "pop from empty ring")
+
+	for {
+		if _, err = r.TryPush(1); err != nil {
+			break
+		}
+	}
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	_, err = r.Push(ctx2, 2)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSyncRingCloseWakesWaiters(t *testing.T) {
+	r, err := ring.CreateSync[int](2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errC := make(chan error, 1)
+	go func() {
+		_, err := r.Pop(context.Background())
+		errC <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	r.Close()
+	select {
+	case err = <-errC:
+		assert.ErrorIs(t, err, ring.ErrClosed)
+	case <-time.After(3 * time.Second):
+		t.Fatal("time out")
+	}
+	_, err = r.TryPush(1)
+	assert.ErrorIs(t, err, ring.ErrClosed)
+}
+
+func TestSyncRingNoWaiterAllocs(t *testing.T) {
+	plain, err := ring.Create[int](4)
+	assert.Nil(t, err)
+	expected := testing.AllocsPerRun(100, func() {
+		plain.Append(1)
+		plain.Pop()
+	})
+	r, err := ring.CreateSync[int](4)
+	assert.Nil(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		r.TryPush(1)
+		r.TryPop()
+	})
+	// with nobody waiting, only the Ring itself may allocate
+	assert.Equal(t, expected, allocs)
+}
@@ -0,0 +1,256 @@
+package ring
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// relative accuracy of the percentiles reported by Window
+const WINDOW_PERCENTILE_ACCURACY float64 = 0.01
+
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Stats is a summary of the samples inside a Window
+type Stats struct {
+	Count uint
+	Sum   float64
+	Min   float64
+	Max   float64
+	P50   float64
+	P90   float64
+	P99   float64
+}
+
+// an entry in a monotonic min/max queue
+type extreme struct {
+	id    uint64
+	value float64
+}
+
+// Window is a ring of timestamped samples that evicts samples older than a
+// fixed duration. Count, sum, min and max are kept up to date in O(1)
+// amortized time per sample. Percentiles come from a log-bucketed
+// histogram and are within WINDOW_PERCENTILE_ACCURACY of the true value.
+// Negative values are counted as zero by the percentiles.
+type Window struct {
+	r        *Ring[Sample]
+	duration time.Duration
+	sum      float64
+	minQ     []extreme
+	maxQ     []extreme
+	gamma    float64
+	logGamma float64
+	buckets  map[int]uint
+}
+
+// create a window holding at most size samples that are no older than duration
+func CreateWindow(duration time.Duration, size uint64) (*Window, error) {
+	if duration <= 0 {
+		return nil, errors.New("duration is not positive")
+	}
+	r, err := Create[Sample](size)
+	if err != nil {
+		return nil, err
+	}
+	w := new(Window)
+	w.r = r
+	w.duration = duration
+	w.sum = 0
+	w.minQ = make([]extreme, 0)
+	w.maxQ = make([]extreme, 0)
+	w.gamma = (1 + WINDOW_PERCENTILE_ACCURACY) / (1 - WINDOW_PERCENTILE_ACCURACY)
+	w.logGamma = math.Log(w.gamma)
+	w.buckets = make(map[int]uint)
+	return w, nil
+}
+
+// returns the number of samples in the window
+func (w *Window) Length() uint {
+	return w.r.Length()
+}
+
+// returns the duration covered by the window
+func (w *Window) Duration() time.Duration {
+	return w.duration
+}
+
+// bucket i covers (gamma^(i-1), gamma^i]; zero and negative values share
+// their own bucket
+func (w *Window) bucket(v float64) int {
+	if v <= 0 {
+		return math.MinInt32
+	}
+	return int(math.Ceil(math.Log(v) / w.logGamma))
+}
+
+func (w *Window) bucketValue(i int) float64 {
+	if i == math.MinInt32 {
+		return 0
+	}
+	return 2 * math.Pow(w.gamma, float64(i)) / (w.gamma + 1)
+}
+
+// add a sample. Samples must be added in time order.
+func (w *Window) Add(t time.Time, value float64) error {
+	if 0 < w.r.Length() {
+		last, err := w.r.Get(w.r.Length() - 1)
+		if err != nil {
+			return err
+		}
+		if t.Before(last.Time) {
+			return errors.New("sample is older than the last sample")
+		}
+	}
+	w.Expire(t)
+	if w.r.Length() == w.r.Max() {
+		w.evict()
+	}
+	id, err := w.r.Append(Sample{Time: t, Value: value})
+	if err != nil {
+		return err
+	}
+	w.sum += value
+	w.buckets[w.bucket(value)]++
+
+	for 0 < len(w.minQ) && value <= w.minQ[len(w.minQ)-1].value {
+		w.minQ = w.minQ[:len(w.minQ)-1]
+	}
+	w.minQ = append(w.minQ, extreme{id: id, value: value})
+	for 0 < len(w.maxQ) && w.maxQ[len(w.maxQ)-1].value <= value {
+		w.maxQ = w.maxQ[:len(w.maxQ)-1]
+	}
+	w.maxQ = append(w.maxQ, extreme{id: id, value: value})
+	return nil
+}
+
+// remove the oldest sample and take it out of the aggregates
+func (w *Window) evict() {
+	id := w.r.FirstId()
+	s, err := w.r.Pop()
+	if err != nil {
+		return
+	}
+	w.sum -= s.Value
+	if w.r.Length() == 0 {
+		// do not let rounding errors pile up
+		w.sum = 0
+	}
+	b := w.bucket(s.Value)
+	w.buckets[b]--
+	if w.buckets[b] == 0 {
+		delete(w.buckets, b)
+	}
+	if 0 < len(w.minQ) && w.minQ[0].id == id {
+		w.minQ = w.minQ[1:]
+	}
+	if 0 < len(w.maxQ) && w.maxQ[0].id == id {
+		w.maxQ = w.maxQ[1:]
+	}
+}
+
+// evict all samples that have fallen out of the window as of now
+func (w *Window) Expire(now time.Time) {
+	cutoff := now.Add(-w.duration)
+	for 0 < w.r.Length() {
+		s, err := w.r.Get(0)
+		if err != nil || cutoff.Before(s.Time) {
+			return
+		}
+		w.evict()
+	}
+}
+
+func (w *Window) Sum() float64 {
+	return w.sum
+}
+
+// returns the mean of the samples, or 0 if the window is empty
+func (w *Window) Mean() float64 {
+	if w.r.Length() == 0 {
+		return 0
+	}
+	return w.sum / float64(w.r.Length())
+}
+
+func (w *Window) Min() (ans float64, is_present bool) {
+	if len(w.minQ) == 0 {
+		return
+	}
+	return w.minQ[0].value, true
+}
+
+func (w *Window) Max() (ans float64, is_present bool) {
+	if len(w.maxQ) == 0 {
+		return
+	}
+	return w.maxQ[0].value, true
+}
+
+// returns the approximate q-th quantile, with q in [0, 1]
+func (w *Window) Percentile(q float64) (ans float64, err error) {
+	list, err := w.percentiles([]float64{q})
+	if err != nil {
+		return
+	}
+	ans = list[0]
+	return
+}
+
+// compute several quantiles in one pass over the histogram
+func (w *Window) percentiles(qList []float64) ([]float64, error) {
+	n := w.r.Length()
+	if n == 0 {
+		return nil, errors.New("window is empty")
+	}
+	keys := make([]int, 0, len(w.buckets))
+	for k := range w.buckets {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	ans := make([]float64, len(qList))
+	for j, q := range qList {
+		if q < 0 || 1 < q {
+			return nil, errors.New("quantile must lie in [0, 1]")
+		}
+		rank := uint(math.Ceil(q * float64(n)))
+		if rank == 0 {
+			rank = 1
+		}
+		var seen uint = 0
+		for _, k := range keys {
+			seen += w.buckets[k]
+			if rank <= seen {
+				ans[j] = w.bucketValue(k)
+				break
+			}
+		}
+		// the histogram is approximate, but the extremes are exact
+		if min, ok := w.Min(); ok && ans[j] < min {
+			ans[j] = min
+		}
+		if max, ok := w.Max(); ok && max < ans[j] {
+			ans[j] = max
+		}
+	}
+	return ans, nil
+}
+
+// returns all aggregates at once
+func (w *Window) Stats() Stats {
+	s := Stats{Count: w.Length(), Sum: w.Sum()}
+	s.Min, _ = w.Min()
+	s.Max, _ = w.Max()
+	list, err := w.percentiles([]float64{0.5, 0.9, 0.99})
+	if err == nil {
+		s.P50 = list[0]
+		s.P90 = list[1]
+		s.P99 = list[2]
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package ring_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowEviction(t *testing.T) {
+	w, err := ring.CreateWindow(10*time.Second, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(0, 0)
+	// values go up then down so both min and max have to move
+	values := []float64{5, 1, 9, 3, 7, 2, 8}
+	for i, v := range values {
+		assert.Nil(t, w.Add(start.Add(time.Duration(i)*3*time.Second), v))
+	}
+	// samples at 9s, 12s, 15s and 18s are still inside (8s, 18s]
+	assert.Equal(t, uint(4), w.Length())
+	assert.Equal(t, float64(3+7+2+8), w.Sum())
+	min, ok := w.Min()
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), min)
+	max, ok := w.Max()
+	assert.True(t, ok)
+	assert.Equal(t, float64(8), max)
+
+	w.Expire(start.Add(26 * time.Second))
+	assert.Equal(t, uint(1), w.Length())
+	min, _ = w.Min()
+	assert.Equal(t, float64(8), min)
+
+	assert.NotNil(t, w.Add(start, 1), "accepted a sample out of order")
+
+	w.Expire(start.Add(time.Hour))
+	assert.Equal(t, uint(0), w.Length())
+	_, ok = w.Max()
+	assert.False(t, ok)
+	_, err = w.Percentile(0.5)
+	assert.NotNil(t, err)
+}
+
+func TestWindowCapacity(t *testing.T) {
+	w, err := ring.CreateWindow(time.Hour, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	for i := 1; i <= 5; i++ {
+		assert.Nil(t, w.Add(now, float64(i)))
+	}
+	assert.Equal(t, uint(3), w.Length())
+	assert.Equal(t, float64(3+4+5), w.Sum())
+	assert.Equal(t, float64(4), w.Mean())
+}
+
+func TestWindowPercentiles(t *testing.T) {
+	w, err := ring.CreateWindow(time.Hour, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	for i := 1; i <= 1000; i++ {
+		assert.Nil(t, w.Add(now, float64(i)))
+	}
+	s := w.Stats()
+	assert.Equal(t, uint(1000), s.Count)
+	for _, x := range []struct {
+		got  float64
+		want float64
+	}{{s.P50, 500}, {s.P90, 900}, {s.P99, 990}} {
+		relErr := math.Abs(x.got-x.want) / x.want
+		assert.LessOrEqual(t, relErr, ring.WINDOW_PERCENTILE_ACCURACY+1e-9, "got %f want %f", x.got, x.want)
+	}
+	p, err := w.Percentile(1)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1000), p)
+}
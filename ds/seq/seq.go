@@ -0,0 +1,98 @@
+// Package seq has combinators over iter.Seq so processing code can work the
+// same way on any container in ds. Every container has a Values method
+// producing an iter.Seq; Seq2 producers (All, Prefix) can be narrowed with
+// Keys or Values.
+package seq
+
+import "iter"
+
+// Map yields f(v) for every v in s
+func Map[T any, U any](s iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range s {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter yields the elements of s for which keep returns true
+func Filter[T any](s iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range s {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce folds s into a single value, starting from initial
+func Reduce[T any, A any](s iter.Seq[T], initial A, f func(acc A, v T) A) A {
+	acc := initial
+	for v := range s {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Find returns the first element of s for which pred returns true
+func Find[T any](s iter.Seq[T], pred func(T) bool) (ans T, is_present bool) {
+	for v := range s {
+		if pred(v) {
+			return v, true
+		}
+	}
+	return
+}
+
+// Take yields at most n elements of s
+func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range s {
+			if !yield(v) {
+				return
+			}
+			i++
+			if n <= i {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns the elements of s as a slice
+func Collect[T any](s iter.Seq[T]) []T {
+	ans := make([]T, 0)
+	for v := range s {
+		ans = append(ans, v)
+	}
+	return ans
+}
+
+// Keys yields the first element of every pair in s
+func Keys[K any, V any](s iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields the second element of every pair in s
+func Values[K any, V any](s iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package seq_test
+
+import (
+	"strconv"
+	"testing"
+
+	ll "github.com/solpipe/solpipe-util/ds/list"
+	"github.com/solpipe/solpipe-util/ds/radix"
+	"github.com/solpipe/solpipe-util/ds/ring"
+	"github.com/solpipe/solpipe-util/ds/seq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombinators(t *testing.T) {
+	g := ll.CreateGeneric[int]()
+	g.AppendArray([]int{1, 2, 3, 4, 5, 6})
+
+	even := seq.Filter(g.Values(), func(x int) bool { return x%2 == 0 })
+	assert.Equal(t, []int{2, 4, 6}, seq.Collect(even))
+	assert.Equal(t, []string{"1", "2"}, seq.Collect(seq.Take(seq.Map(g.Values(), strconv.Itoa), 2)))
+	assert.Equal(t, 21, seq.Reduce(g.Values(), 0, func(acc int, x int) int { return acc + x }))
+	assert.Equal(t, 0, len(seq.Collect(seq.Take(g.Values(), 0))))
+
+	x, ok := seq.Find(g.Values(), func(x int) bool { return 3 < x })
+	assert.True(t, ok)
+	assert.Equal(t, 4, x)
+	_, ok = seq.Find(g.Values(), func(x int) bool { return 10 < x })
+	assert.False(t, ok)
+}
+
+func TestContainers(t *testing.T) {
+	g := ll.CreateGeneric[int]()
+	g.AppendArray([]int{1, 2, 3})
+	// removing the current node inside the loop is allowed
+	for _, v := range g.All() {
+		if v == 2 {
+			g.Remove(g.HeadNode().Next())
+		}
+	}
+	assert.Equal(t, []int{1, 3}, seq.Collect(g.Values()))
+	assert.Equal(t, []int{3, 1}, seq.Collect(seq.Values(g.Backward())))
+
+	s := ll.CreateSync[int]()
+	s.Append(7)
+	s.Append(8)
+	for _, v := range s.All() {
+		// no lock is held while the loop body runs
+		s.Append(v * 10)
+	}
+	assert.Equal(t, []int{7, 8, 70, 80}, seq.Collect(s.Values()))
+
+	r, err := ring.Create[int](3)
+	assert.Nil(t, err)
+	for i := 0; i < 5; i++ {
+		r.OverwriteAppend(i)
+	}
+	assert.Equal(t, []uint64{2, 3, 4}, seq.Collect(seq.Keys(r.All())))
+	assert.Equal(t, []int{2, 3, 4}, seq.Collect(r.Values()))
+
+	tree := radix.New[int]()
+	tree.Insert("b", 2)
+	tree.Insert("a", 1)
+	tree.Insert("ab", 3)
+	tree.Insert("c", 4)
+	assert.Equal(t, []string{"a", "ab", "b", "c"}, seq.Collect(seq.Keys(tree.All())))
+	assert.Equal(t, []int{1, 3}, seq.Collect(seq.Values(tree.Prefix("a"))))
+	assert.Equal(t, []string{"a", "ab"}, seq.Collect(seq.Take(seq.Keys(tree.All()), 2)))
+}
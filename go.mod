@@ -1,13 +1,13 @@
 module github.com/solpipe/solpipe-util
 
-go 1.19
+go 1.23
 
 require github.com/stretchr/testify v1.8.1
 
 require (
 	contrib.go.opencensus.io/exporter/stackdriver v0.13.10 // indirect
 	filippo.io/edwards25519 v1.0.0-rc.1 // indirect
-	github.com/SolmateDev/solana-go v1.7.1-custom // indirect
+	github.com/SolmateDev/solana-go v1.7.1-custom
 	github.com/atomixwap/go-merkle v0.1.0
 	github.com/aybabtme/rgbterm v0.0.0-20170906152045-cc83f3b3ce59 // indirect
 	github.com/blendle/zapdriver v1.3.1 // indirect
@@ -39,7 +39,7 @@ require (
 	go.uber.org/multierr v1.8.0 // indirect
 	go.uber.org/zap v1.21.0 // indirect
 	golang.org/x/crypto v0.0.0-20220331220935-ae2d96664a29 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )